@@ -0,0 +1,277 @@
+package calibration
+
+import (
+	"encoding/json"
+	"image"
+	"math"
+	"os"
+	"screwSort/geometry"
+	"screwSort/vision"
+	"sort"
+)
+
+// dotAreaMin is the minimum area in pixels² of a dot of a calibration target
+const dotAreaMin = 10.
+
+// Calibration describes the conversion from pixels to millimetres with a separate scale along x and y
+//
+// The zero Calibration is uncalibrated and leaves all measurements in pixels
+type Calibration struct {
+	sx, sy float64
+}
+
+// CalibrationScale constructs an isotropic Calibration given the millimetres per pixel
+func CalibrationScale(s float64) Calibration {
+	return Calibration{s, s}
+}
+
+// CalibrationXY constructs an anisotropic Calibration given the millimetres per pixel along x and y
+func CalibrationXY(sx, sy float64) Calibration {
+	return Calibration{sx, sy}
+}
+
+// CalibrationCircle constructs an isotropic Calibration from the Hull of a circular reference object
+// with the known diameter in millimetres
+func CalibrationCircle(h vision.Hull, d float64) (Calibration, error) {
+	if !(d > 0) {
+		return Calibration{}, ErrInvalidLength
+	}
+	a := h.Area()
+	if !(a > 0) || math.IsInf(a, 1) {
+		return Calibration{}, ErrDegenerateReference
+	}
+	return CalibrationScale(d / (2 * math.Sqrt(a/math.Pi))), nil
+}
+
+// CalibrationBounds constructs an anisotropic Calibration from the Hull of an axis-aligned reference object
+// with the known width and height in millimetres
+func CalibrationBounds(h vision.Hull, dx, dy float64) (Calibration, error) {
+	if !(dx > 0) || !(dy > 0) {
+		return Calibration{}, ErrInvalidLength
+	}
+	if len(h.Ps()) == 0 {
+		return Calibration{}, ErrDegenerateReference
+	}
+	pMin, pMax := h.Bounds()
+	w, l := pMax.X()-pMin.X(), pMax.Y()-pMin.Y()
+	if !(w > 0) || !(l > 0) {
+		return Calibration{}, ErrDegenerateReference
+	}
+	return CalibrationXY(dx/w, dy/l), nil
+}
+
+// CalibrationDots constructs an anisotropic Calibration from an image of an axis-aligned grid of dark dots
+// spaced by the known pitch in millimetres
+//
+// The spacing in pixels along each axis is the median distance of every dot to its nearest neighbor along that axis.
+// Dots cut by the border of the image are left out since their centroids are pulled inward
+func CalibrationDots(im *image.Gray, pitch float64) (Calibration, error) {
+	if !(pitch > 0) {
+		return Calibration{}, ErrInvalidLength
+	}
+	var cs []geometry.Point
+	for _, h := range vision.SuperHulls(im, vision.AutoLevel, dotAreaMin) {
+		if !h.Truncated() {
+			cs = append(cs, h.Centroid())
+		}
+	}
+
+	var dxs, dys []float64
+	for i, c := range cs {
+		dx, dy := math.Inf(1), math.Inf(1)
+		for j, d := range cs {
+			if i == j {
+				continue
+			}
+			v := d.Subtract(c)
+			if math.Abs(v.X()) > math.Abs(v.Y()) {
+				dx = math.Min(dx, c.DistanceTo(d))
+			} else {
+				dy = math.Min(dy, c.DistanceTo(d))
+			}
+		}
+		if !math.IsInf(dx, 1) {
+			dxs = append(dxs, dx)
+		}
+		if !math.IsInf(dy, 1) {
+			dys = append(dys, dy)
+		}
+	}
+	if len(dxs) == 0 || len(dys) == 0 {
+		return Calibration{}, ErrTooFewDots
+	}
+	return CalibrationXY(pitch/median(dxs), pitch/median(dys)), nil
+}
+
+// CalibrationChecker constructs an anisotropic Calibration from an image of an axis-aligned checkerboard
+// whose squares have the known side in millimetres
+//
+// The edges between squares are located at sub-pixel accuracy as the peaks of the horizontal and vertical gradients
+// summed along the columns and rows, and the spacing in pixels along each axis is the median distance between
+// successive edges
+func CalibrationChecker(im *image.Gray, side float64) (Calibration, error) {
+	if !(side > 0) {
+		return Calibration{}, ErrInvalidLength
+	}
+	g := vision.SobelGradient(im)
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	cols, rows := make([]float64, dx), make([]float64, dy)
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			gx, gy := g.At(x+im.Rect.Min.X, y+im.Rect.Min.Y)
+			cols[x] += math.Abs(gx)
+			rows[y] += math.Abs(gy)
+		}
+	}
+	sx, sy := median(spacings(profilePeaks(cols))), median(spacings(profilePeaks(rows)))
+	if math.IsNaN(sx) || math.IsNaN(sy) {
+		return Calibration{}, ErrTooFewSquares
+	}
+	return CalibrationXY(side/sx, side/sy), nil
+}
+
+// LoadCalibration reads a Calibration saved with Save from the file
+//
+// It returns ErrInvalidScale if either scale in the file is not positive and finite
+func LoadCalibration(fn string) (Calibration, error) {
+	bs, err := os.ReadFile(fn)
+	if err != nil {
+		return Calibration{}, err
+	}
+	var f calibrationFile
+	if err = json.Unmarshal(bs, &f); err != nil {
+		return Calibration{}, err
+	}
+	if !(f.Sx > 0) || !(f.Sy > 0) || math.IsInf(f.Sx, 1) || math.IsInf(f.Sy, 1) {
+		return Calibration{}, ErrInvalidScale
+	}
+	return CalibrationXY(f.Sx, f.Sy), nil
+}
+
+// Save writes the Calibration to the file
+func (c Calibration) Save(fn string) error {
+	bs, err := json.MarshalIndent(calibrationFile{c.sx, c.sy}, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fn, bs, 0644)
+}
+
+// IsCalibrated returns whether the Calibration converts to millimetres
+func (c Calibration) IsCalibrated() bool {
+	return c.sx > 0 && c.sy > 0
+}
+
+// Sx returns the millimetres per pixel along x
+func (c Calibration) Sx() float64 {
+	return c.sx
+}
+
+// Sy returns the millimetres per pixel along y
+func (c Calibration) Sy() float64 {
+	return c.sy
+}
+
+// Point returns the Point converted to millimetres
+func (c Calibration) Point(p geometry.Point) geometry.Point {
+	if !c.IsCalibrated() {
+		return p
+	}
+	return geometry.PointXY(c.sx*p.X(), c.sy*p.Y())
+}
+
+// Segment returns the Segment converted to millimetres
+func (c Calibration) Segment(s geometry.Segment) geometry.Segment {
+	return geometry.SegmentPQ(c.Point(s.P()), c.Point(s.Q()))
+}
+
+// Length returns the length of the Segment in millimetres
+func (c Calibration) Length(s geometry.Segment) float64 {
+	return c.Segment(s).Length()
+}
+
+// Area returns the area in pixels² converted to millimetres²
+func (c Calibration) Area(a float64) float64 {
+	if !c.IsCalibrated() {
+		return a
+	}
+	return c.sx * c.sy * a
+}
+
+// PixelArea returns the area in millimetres² converted to pixels², such as the minimum area of hull extraction
+func (c Calibration) PixelArea(a float64) float64 {
+	if !c.IsCalibrated() {
+		return a
+	}
+	return a / (c.sx * c.sy)
+}
+
+// Hull returns a new Hull with its points converted to millimetres
+func (c Calibration) Hull(h vision.Hull) vision.Hull {
+	return h.Map(c.Point)
+}
+
+// Region returns a new Region with its outer boundary and holes converted to millimetres
+func (c Calibration) Region(r vision.Region) vision.Region {
+	hs := make([]vision.Hull, len(r.Holes()))
+	for i, h := range r.Holes() {
+		hs[i] = c.Hull(h)
+	}
+	return vision.RegionHulls(c.Hull(r.Outer()), hs...)
+}
+
+// profilePeaks returns the weighted centers of the runs of the profile above half its maximum
+// in pixel-center coordinates
+func profilePeaks(vs []float64) []float64 {
+	vMax := 0.
+	for _, v := range vs {
+		vMax = math.Max(vMax, v)
+	}
+	if vMax == 0 {
+		return nil
+	}
+	var ps []float64
+	var s, sw float64
+	for i, v := range vs {
+		if v > vMax/2 {
+			s += (float64(i) + 0.5) * v
+			sw += v
+			continue
+		}
+		if sw > 0 {
+			ps = append(ps, s/sw)
+		}
+		s, sw = 0, 0
+	}
+	if sw > 0 {
+		ps = append(ps, s/sw)
+	}
+	return ps
+}
+
+// spacings returns the differences between successive values
+func spacings(vs []float64) []float64 {
+	var ds []float64
+	for i := 1; i < len(vs); i++ {
+		ds = append(ds, vs[i]-vs[i-1])
+	}
+	return ds
+}
+
+type calibrationFile struct {
+	Sx float64 `json:"sx"`
+	Sy float64 `json:"sy"`
+}
+
+func median(vs []float64) float64 {
+	if len(vs) == 0 {
+		return math.NaN()
+	}
+	vs = append([]float64(nil), vs...)
+	sort.Float64s(vs)
+	n := len(vs)
+	if n%2 == 1 {
+		return vs[n/2]
+	}
+	return (vs[n/2-1] + vs[n/2]) / 2
+}
@@ -0,0 +1,169 @@
+package calibration
+
+import (
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"screwSort/geometry"
+	"screwSort/vision"
+	"testing"
+)
+
+// checkerImage returns an image of a checkerboard with squares of sx×sy pixels starting at the offset
+func checkerImage(dx, dy, sx, sy, offset int) *image.Gray {
+	im := image.NewGray(image.Rect(0, 0, dx, dy))
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			if ((x+offset)/sx+(y+offset)/sy)%2 == 0 {
+				im.Pix[y*im.Stride+x] = 230
+			} else {
+				im.Pix[y*im.Stride+x] = 20
+			}
+		}
+	}
+	return im
+}
+
+// dotImage returns an image of a grid of dark square dots of side 5 pixels spaced by sx and sy pixels
+func dotImage(dx, dy, sx, sy int) *image.Gray {
+	im := image.NewGray(image.Rect(0, 0, dx, dy))
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			im.Pix[y*im.Stride+x] = 230
+			if x%sx >= 4 && x%sx < 9 && y%sy >= 4 && y%sy < 9 {
+				im.Pix[y*im.Stride+x] = 20
+			}
+		}
+	}
+	return im
+}
+
+func TestCalibrationChecker(t *testing.T) {
+	tests := []struct {
+		name           string
+		im             *image.Gray
+		side           float64
+		wantSx, wantSy float64
+		err            error
+	}{
+		{"isotropic", checkerImage(100, 80, 12, 12, 5), 6, 0.5, 0.5, nil},
+		{"anisotropic", checkerImage(120, 90, 15, 10, 3), 3, 0.2, 0.3, nil},
+		{"single square", checkerImage(20, 20, 40, 40, 0), 3, 0, 0, ErrTooFewSquares},
+		{"blank", image.NewGray(image.Rect(0, 0, 20, 20)), 3, 0, 0, ErrTooFewSquares},
+		{"zero side", checkerImage(100, 80, 12, 12, 5), 0, 0, 0, ErrInvalidLength},
+	}
+	for _, tt := range tests {
+		c, err := CalibrationChecker(tt.im, tt.side)
+		if err != tt.err {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err == nil && (math.Abs(c.Sx()-tt.wantSx) > 1e-9 || math.Abs(c.Sy()-tt.wantSy) > 1e-9) {
+			t.Errorf("%s: got (%v, %v) mm per pixel, want (%v, %v)", tt.name, c.Sx(), c.Sy(), tt.wantSx, tt.wantSy)
+		}
+	}
+}
+
+func TestCalibrationDots(t *testing.T) {
+	tests := []struct {
+		name           string
+		im             *image.Gray
+		wantSx, wantSy float64
+		err            error
+	}{
+		{"grid", dotImage(100, 80, 20, 16), 0.1, 0.125, nil},
+		// dots cut by the border have their centroids pulled inward, so they are left out
+		{"grid cut by the border", dotImage(68, 56, 20, 16).SubImage(image.Rect(5, 5, 68, 56)).(*image.Gray), 0.1, 0.125, nil},
+		{"single dot", dotImage(15, 15, 20, 20), 0, 0, ErrTooFewDots},
+		{"single dot among cut dots", dotImage(28, 28, 20, 20), 0, 0, ErrTooFewDots},
+		{"blank", image.NewGray(image.Rect(0, 0, 20, 20)), 0, 0, ErrTooFewDots},
+	}
+	for _, tt := range tests {
+		c, err := CalibrationDots(tt.im, 2)
+		if err != tt.err {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err == nil && (math.Abs(c.Sx()-tt.wantSx) > 1e-6 || math.Abs(c.Sy()-tt.wantSy) > 1e-6) {
+			t.Errorf("%s: got (%v, %v) mm per pixel, want (%v, %v)", tt.name, c.Sx(), c.Sy(), tt.wantSx, tt.wantSy)
+		}
+	}
+}
+
+func TestCalibrationReference(t *testing.T) {
+	square := vision.HullPs([]geometry.Point{
+		geometry.PointXY(0, 0), geometry.PointXY(20, 0), geometry.PointXY(20, 10), geometry.PointXY(0, 10),
+	})
+	flat := vision.HullPs([]geometry.Point{geometry.PointXY(0, 0), geometry.PointXY(20, 0)})
+	empty := vision.HullPs(nil)
+
+	if c, err := CalibrationBounds(square, 10, 10); err != nil || c.Sx() != 0.5 || c.Sy() != 1 {
+		t.Errorf("CalibrationBounds: got (%v, %v), %v, want (0.5, 1)", c.Sx(), c.Sy(), err)
+	}
+	if c, err := CalibrationCircle(square, 2*math.Sqrt(200/math.Pi)); err != nil || math.Abs(c.Sx()-1) > 1e-12 {
+		t.Errorf("CalibrationCircle: got %v, %v, want 1", c.Sx(), err)
+	}
+	errs := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"CalibrationBounds of a flat Hull", second(CalibrationBounds(flat, 10, 10)), ErrDegenerateReference},
+		{"CalibrationBounds of an empty Hull", second(CalibrationBounds(empty, 10, 10)), ErrDegenerateReference},
+		{"CalibrationBounds with zero height", second(CalibrationBounds(square, 10, 0)), ErrInvalidLength},
+		{"CalibrationCircle of a flat Hull", second(CalibrationCircle(flat, 10)), ErrDegenerateReference},
+		{"CalibrationCircle of an empty Hull", second(CalibrationCircle(empty, 10)), ErrDegenerateReference},
+		{"CalibrationCircle with NaN diameter", second(CalibrationCircle(square, math.NaN())), ErrInvalidLength},
+	}
+	for _, tt := range errs {
+		if tt.err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+}
+
+func TestLoadCalibration(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "calibration.json")
+	if err := CalibrationXY(0.05, 0.0625).Save(fn); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := LoadCalibration(fn); err != nil || c.Sx() != 0.05 || c.Sy() != 0.0625 {
+		t.Errorf("got (%v, %v), %v, want (0.05, 0.0625)", c.Sx(), c.Sy(), err)
+	}
+
+	tests := []struct {
+		name string
+		json string
+		want error
+	}{
+		{"zero", `{"sx": 0, "sy": 0.1}`, ErrInvalidScale},
+		{"negative", `{"sx": 0.1, "sy": -0.1}`, ErrInvalidScale},
+		{"missing", `{"sx": 0.1}`, ErrInvalidScale},
+		{"uncalibrated", `{"sx": 0, "sy": 0}`, ErrInvalidScale},
+	}
+	for _, tt := range tests {
+		fn := filepath.Join(dir, tt.name+".json")
+		if err := os.WriteFile(fn, []byte(tt.json), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCalibration(fn); err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	// JSON has no NaN or infinity, so they cannot be read as scales
+	for _, s := range []string{`{"sx": NaN, "sy": 0.1}`, `{"sx": 1e400, "sy": 0.1}`} {
+		fn := filepath.Join(dir, "invalid.json")
+		if err := os.WriteFile(fn, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCalibration(fn); err == nil {
+			t.Errorf("%s: got no error", s)
+		}
+	}
+}
+
+func second(_ Calibration, err error) error {
+	return err
+}
@@ -0,0 +1,16 @@
+package calibration
+
+import "errors"
+
+var (
+	// ErrInvalidLength is returned when a known length of a reference is not positive
+	ErrInvalidLength = errors.New("calibration: failed to satisfy length > 0")
+	// ErrDegenerateReference is returned when the Hull of a reference object has no extent along a measured axis
+	ErrDegenerateReference = errors.New("calibration: reference object has no extent")
+	// ErrInvalidScale is returned when a loaded scale is not positive and finite
+	ErrInvalidScale = errors.New("calibration: failed to satisfy 0 < scale < ∞")
+	// ErrTooFewDots is returned when a dot target does not have two dots along each axis
+	ErrTooFewDots = errors.New("calibration: fewer than two dots found along an axis")
+	// ErrTooFewSquares is returned when a checker target does not have two square edges along each axis
+	ErrTooFewSquares = errors.New("calibration: fewer than two square edges found along an axis")
+)
@@ -87,7 +87,9 @@ func OrthogonalFit(ps []geometry.Point) Fit {
 	} else {
 		a = 0
 	}
-	l := geometry.LineAC(a, -a*xBar-math.Sqrt(1-a*a)*yBar)
+	a = math.Max(-1, math.Min(1, a))
+	bc := math.Sqrt(1 - a*a)
+	l := geometry.LineABC(a, bc, -a*xBar-bc*yBar)
 
 	es := make([]float64, len(ps))
 	for i, p := range ps {
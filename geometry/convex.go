@@ -0,0 +1,55 @@
+package geometry
+
+import "sort"
+
+// ConvexHull returns the vertices of the convex hull of the Points counterclockwise from the leftmost, topmost one,
+// without duplicate or collinear vertices
+func ConvexHull(ps []Point) []Point {
+	is := ConvexHullIndices(ps)
+	qs := make([]Point, len(is))
+	for k, i := range is {
+		qs[k] = ps[i]
+	}
+	return qs
+}
+
+// ConvexHullIndices returns the indices of the vertices of the convex hull of the Points as ordered by ConvexHull
+// using Andrew's monotone chain with exact orientation, taking the first of duplicate Points
+func ConvexHullIndices(ps []Point) []int {
+	is := make([]int, len(ps))
+	for i := range is {
+		is[i] = i
+	}
+	sort.SliceStable(is, func(i, j int) bool {
+		p, q := ps[is[i]], ps[is[j]]
+		return p.x < q.x || p.x == q.x && p.y < q.y
+	})
+	u := is[:0]
+	for k, i := range is {
+		if k == 0 || ps[i] != ps[u[len(u)-1]] {
+			u = append(u, i)
+		}
+	}
+	if len(u) < 3 {
+		return append([]int(nil), u...)
+	}
+
+	// with y pointing down, the lower chain on screen turns counterclockwise from left to right
+	// and the upper chain back from right to left
+	hs := make([]int, 0, 2*len(u))
+	for _, i := range u {
+		for len(hs) >= 2 && ps[hs[len(hs)-2]].OrientationSign(ps[hs[len(hs)-1]], ps[i]) >= 0 {
+			hs = hs[:len(hs)-1]
+		}
+		hs = append(hs, i)
+	}
+	lower := len(hs) + 1
+	for k := len(u) - 2; k >= 0; k-- {
+		i := u[k]
+		for len(hs) >= lower && ps[hs[len(hs)-2]].OrientationSign(ps[hs[len(hs)-1]], ps[i]) >= 0 {
+			hs = hs[:len(hs)-1]
+		}
+		hs = append(hs, i)
+	}
+	return hs[:len(hs)-1]
+}
@@ -0,0 +1,117 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+// pointsXY returns the Points given as consecutive x and y coordinates
+func pointsXY(vs ...float64) []Point {
+	ps := make([]Point, len(vs)/2)
+	for i := range ps {
+		ps[i] = PointXY(vs[2*i], vs[2*i+1])
+	}
+	return ps
+}
+
+func TestOrientationSign(t *testing.T) {
+	tests := []struct {
+		name    string
+		p, a, b Point
+		want    int
+	}{
+		// y points down, so turning right then down is clockwise on screen
+		{"clockwise", PointXY(0, 0), PointXY(1, 0), PointXY(1, 1), 1},
+		{"counterclockwise", PointXY(0, 0), PointXY(1, 1), PointXY(1, 0), -1},
+		{"collinear", PointXY(0, 0), PointXY(1, 1), PointXY(3, 3), 0},
+		{"collinear backwards", PointXY(0, 0), PointXY(3, 3), PointXY(1, 1), 0},
+		{"duplicate first", PointXY(2, 5), PointXY(2, 5), PointXY(7, 1), 0},
+		{"duplicate last", PointXY(2, 5), PointXY(7, 1), PointXY(7, 1), 0},
+		{"all the same", PointXY(2, 5), PointXY(2, 5), PointXY(2, 5), 0},
+		// the rounded determinant of these points is not 0, but they lie on the line y = x exactly
+		{"collinear far from the origin", PointXY(0.5, 0.5), PointXY(12, 12), PointXY(24, 24), 0},
+		{"collinear with large offsets", PointXY(1e15+1, 1e15+1), PointXY(1e15+3, 1e15+3), PointXY(1e15+7, 1e15+7), 0},
+		{"barely clockwise", PointXY(0, 0), PointXY(1e8, 0), PointXY(2e8, 0x1p-30), 1},
+		{"barely counterclockwise", PointXY(0, 0), PointXY(1e8, 0), PointXY(2e8, -0x1p-30), -1},
+	}
+	for _, tt := range tests {
+		if got := tt.p.OrientationSign(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: OrientationSign = %d, want %d", tt.name, got, tt.want)
+		}
+		// the orientation changes sign with the order of the points
+		if got := tt.p.OrientationSign(tt.b, tt.a); got != -tt.want {
+			t.Errorf("%s: reversed OrientationSign = %d, want %d", tt.name, got, -tt.want)
+		}
+	}
+}
+
+func TestConvexHullIndices(t *testing.T) {
+	tests := []struct {
+		name string
+		ps   []Point
+		want []int
+	}{
+		{"empty", nil, []int{}},
+		{"one point", pointsXY(3, 4), []int{0}},
+		{"duplicates of one point", pointsXY(3, 4, 3, 4, 3, 4), []int{0}},
+		{"two points", pointsXY(5, 5, 1, 2), []int{1, 0}},
+		// collinear points keep only the ends of their segment
+		{"collinear", pointsXY(2, 2, 0, 0, 3, 3, 1, 1), []int{1, 2}},
+		{"collinear with duplicates", pointsXY(1, 1, 0, 0, 1, 1, 2, 2, 0, 0), []int{1, 3}},
+		// counterclockwise on screen from the leftmost, topmost point goes down first
+		{"square", pointsXY(0, 0, 4, 0, 4, 4, 0, 4), []int{0, 3, 2, 1}},
+		{"square with an inner point", pointsXY(2, 2, 0, 0, 4, 0, 4, 4, 0, 4), []int{1, 4, 3, 2}},
+		{"square with points on its edges", pointsXY(0, 0, 2, 0, 4, 0, 4, 2, 4, 4, 2, 4, 0, 4, 0, 2), []int{0, 6, 4, 2}},
+		{"square with duplicate corners", pointsXY(0, 0, 4, 0, 4, 4, 0, 0, 0, 4, 4, 0), []int{0, 4, 2, 1}},
+		{"triangle", pointsXY(0, 0, 6, 0, 0, 3), []int{0, 2, 1}},
+	}
+	for _, tt := range tests {
+		got := ConvexHullIndices(tt.ps)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+		qs := ConvexHull(tt.ps)
+		for i, q := range qs {
+			if q != tt.ps[got[i]] {
+				t.Errorf("%s: ConvexHull differs from ConvexHullIndices at %d", tt.name, i)
+			}
+		}
+	}
+}
+
+func TestConvexHullProperties(t *testing.T) {
+	// points on a coarse grid have many duplicates and collinear triples
+	var ps []Point
+	for i := 0; i < 200; i++ {
+		ps = append(ps, PointXY(float64((i*37)%11), float64((i*53)%7)))
+	}
+	for i := 0; i < 40; i++ {
+		a := 0.3 * float64(i)
+		ps = append(ps, PointXY(5+8*math.Cos(a), 3+8*math.Sin(a)))
+	}
+	hs := ConvexHull(ps)
+	n := len(hs)
+	if n < 3 {
+		t.Fatalf("got %d vertices", n)
+	}
+	for i := range hs {
+		a, b, c := hs[i], hs[(i+1)%n], hs[(i+2)%n]
+		// every turn is strictly counterclockwise, so there are no duplicate or collinear vertices
+		if s := a.OrientationSign(b, c); s != -1 {
+			t.Errorf("vertices %d, %d, %d have orientation %d, want -1", i, (i+1)%n, (i+2)%n, s)
+		}
+		// and every point lies on the inner side of or on every edge
+		for _, p := range ps {
+			if a.OrientationSign(b, p) > 0 {
+				t.Errorf("point %v is outside edge %v, %v", p, a, b)
+			}
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package geometry
+
+import "errors"
+
+var (
+	// ErrParallel is returned when an intersection is requested between parallel lines or segments
+	ErrParallel = errors.New("geometry: parallel lines do not intersect")
+	// ErrOutsideWindow is returned when a Line does not pass through the requested window
+	ErrOutsideWindow = errors.New("geometry: line does not pass through the window")
+	// ErrUndefinedSegment is returned when a Segment has non-finite end points
+	ErrUndefinedSegment = errors.New("geometry: segment is not well-defined")
+	// ErrInvalidCoefficient is returned when a Line coefficient cannot satisfy a²+b²=1
+	ErrInvalidCoefficient = errors.New("geometry: failed to satisfy |a| <= 1")
+)
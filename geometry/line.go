@@ -28,12 +28,12 @@ func LineMY0(m, y0 float64) Line {
 
 // LineAC constructs a Line given the a and c coefficients in ax+by+c=0 assuming a²+b²=1
 //
-// It panics if |a| > 1
-func LineAC(a, c float64) Line {
+// It returns ErrInvalidCoefficient if |a| > 1
+func LineAC(a, c float64) (Line, error) {
 	if math.Abs(a) > 1 {
-		panic("failed to satisfy |a| <= 1")
+		return Line{}, ErrInvalidCoefficient
 	}
-	return Line{a, math.Sqrt(1 - a*a), c}
+	return Line{a, math.Sqrt(1 - a*a), c}, nil
 }
 
 // LineABC constructs a Line given the three coefficients in ax+by+c=0
@@ -118,20 +118,20 @@ func (l Line) AngleBetween(o Line) float64 {
 
 // IntersectionWith returns the intersection Point of the Line with the other Line
 //
-// It panics if the two are parallel
-func (l Line) IntersectionWith(o Line) Point {
+// It returns ErrParallel if the two are parallel
+func (l Line) IntersectionWith(o Line) (Point, error) {
 	d := l.a*o.b - l.b*o.a
 	if d == 0 {
-		panic("parallel lines do not intersect")
+		return Point{}, ErrParallel
 	}
-	return PointXY((l.b*o.c-l.c*o.b)/d, (l.c*o.a-l.a*o.c)/d)
+	return PointXY((l.b*o.c-l.c*o.b)/d, (l.c*o.a-l.a*o.c)/d), nil
 }
 
 // ToSegment returns the Segment of the Line that is inside the rectangular window
 // represented by its top-left Point and bottom-right Point
 //
-// It panics if the Line does not overlap with the window
-func (l Line) ToSegment(pTL, pBR Point) Segment {
+// It returns ErrOutsideWindow if the Line does not overlap with the window
+func (l Line) ToSegment(pTL, pBR Point) (Segment, error) {
 	pTR, pBL := PointXY(pBR.x, pTL.y), PointXY(pTL.x, pBR.y)
 	var ps []Point
 
@@ -149,16 +149,22 @@ func (l Line) ToSegment(pTL, pBR Point) Segment {
 	}
 
 	if len(ps) != 2 {
-		panic("line does not pass through the window")
+		return Segment{}, ErrOutsideWindow
 	}
-	return SegmentPQ(ps[0], ps[1])
+	return SegmentPQ(ps[0], ps[1]), nil
 }
 
 // Draw paints the Line on the image with the given color
-func (l Line) Draw(im *image.RGBA, c color.RGBA) {
+//
+// It returns ErrOutsideWindow if the Line does not pass through the image
+func (l Line) Draw(im *image.RGBA, c color.RGBA) error {
 	b := im.Bounds()
-	l.ToSegment(
+	s, err := l.ToSegment(
 		PointXY(float64(b.Min.X), float64(b.Min.Y)),
 		PointXY(float64(b.Max.X), float64(b.Max.Y)),
-	).Draw(im, c)
+	)
+	if err != nil {
+		return err
+	}
+	return s.Draw(im, c)
 }
@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"math/big"
 	"screwSort/utility"
 )
 
@@ -123,6 +124,29 @@ func (p Point) OrientationOf(a, b Point) float64 {
 	return p.Cross(a) + a.Cross(b) + b.Cross(p)
 }
 
+// OrientationSign returns 1 if pab is clockwise, -1 if it is counterclockwise, and 0 if it is collinear,
+// evaluated exactly when rounding could change the sign of OrientationOf
+func (p Point) OrientationSign(a, b Point) int {
+	l, r := (a.x-p.x)*(b.y-p.y), (a.y-p.y)*(b.x-p.x)
+	d := l - r
+	// error bound of the floating-point determinant from Shewchuk's adaptive predicates
+	const eps = 0x1p-53
+	if bound := (3 + 16*eps) * eps * (math.Abs(l) + math.Abs(r)); d > bound {
+		return 1
+	} else if d < -bound {
+		return -1
+	}
+	rat := func(v float64) *big.Rat {
+		return new(big.Rat).SetFloat64(v)
+	}
+	sub := func(u, v float64) *big.Rat {
+		return new(big.Rat).Sub(rat(u), rat(v))
+	}
+	el := new(big.Rat).Mul(sub(a.x, p.x), sub(b.y, p.y))
+	er := new(big.Rat).Mul(sub(a.y, p.y), sub(b.x, p.x))
+	return el.Cmp(er)
+}
+
 // ToImage returns the x and y coordinates of the pixel corresponding to the Point
 func (p Point) ToImage() (int, int) {
 	return utility.IntRound(p.x - 0.5), utility.IntRound(p.y - 0.5)
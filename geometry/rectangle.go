@@ -0,0 +1,85 @@
+package geometry
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Rectangle describes a 2D rectangle by its center, its width along its rotated x-axis,
+// its height along its rotated y-axis, and its angle clockwise from +x
+type Rectangle struct {
+	center        Point
+	width, height float64
+	angle         float64
+}
+
+// String returns a string representation of the Rectangle
+func (r Rectangle) String() string {
+	return fmt.Sprintf("Rectangle{%s, %.2f×%.2f, %.2f}", r.center, r.width, r.height, r.angle)
+}
+
+// RectangleCWHA constructs a Rectangle from its center, width, height, and angle clockwise from +x
+func RectangleCWHA(c Point, width, height, angle float64) Rectangle {
+	return Rectangle{c, width, height, angle}
+}
+
+// Center returns the center Point of the Rectangle
+func (r Rectangle) Center() Point {
+	return r.center
+}
+
+// Width returns the side length of the Rectangle along its rotated x-axis
+func (r Rectangle) Width() float64 {
+	return r.width
+}
+
+// Height returns the side length of the Rectangle along its rotated y-axis
+func (r Rectangle) Height() float64 {
+	return r.height
+}
+
+// Angle returns the angle of the Rectangle clockwise from +x
+func (r Rectangle) Angle() float64 {
+	return r.angle
+}
+
+// Length returns the longer side length of the Rectangle
+func (r Rectangle) Length() float64 {
+	return math.Max(r.width, r.height)
+}
+
+// Breadth returns the shorter side length of the Rectangle
+func (r Rectangle) Breadth() float64 {
+	return math.Min(r.width, r.height)
+}
+
+// Area returns the area of the Rectangle
+func (r Rectangle) Area() float64 {
+	return r.width * r.height
+}
+
+// Corners returns the corner Points of the Rectangle in clockwise order
+func (r Rectangle) Corners() [4]Point {
+	dx, dy := r.width/2, r.height/2
+	return [4]Point{
+		r.center.Translate(-dx, -dy).RotateAbout(r.center, r.angle),
+		r.center.Translate(dx, -dy).RotateAbout(r.center, r.angle),
+		r.center.Translate(dx, dy).RotateAbout(r.center, r.angle),
+		r.center.Translate(-dx, dy).RotateAbout(r.center, r.angle),
+	}
+}
+
+// Draw paints the outline of the Rectangle on the image with the given color
+//
+// It returns ErrUndefinedSegment if the Rectangle is not well-defined
+func (r Rectangle) Draw(im *image.RGBA, c color.RGBA) error {
+	ps := r.Corners()
+	for i, p := range ps {
+		if err := SegmentPQ(p, ps[(i+1)%4]).Draw(im, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -99,6 +99,17 @@ func (s Segment) PerpDistanceTo(p Point) float64 {
 	return math.Abs(d.Cross(p)+s.p.Cross(s.q)) / d.R()
 }
 
+// DistanceTo returns the distance of the Point from the nearest point of the Segment
+func (s Segment) DistanceTo(p Point) float64 {
+	d := s.D()
+	l2 := d.Dot(d)
+	if l2 == 0 {
+		return s.p.DistanceTo(p)
+	}
+	t := math.Max(0, math.Min(1, p.Subtract(s.p).Dot(d)/l2))
+	return s.p.Add(d.Scale(t)).DistanceTo(p)
+}
+
 // SideOf returns positive if the Point is under the Segment, negative if it is above, and 0 if it is on
 func (s Segment) SideOf(p Point) float64 {
 	return s.Dx() * p.OrientationOf(s.p, s.q)
@@ -119,10 +130,15 @@ func (s Segment) IntersectionOf(t Segment) float64 {
 	}
 }
 
-// IntersectionWith returns the intersection Point of the Segment with the other Segment
-func (s Segment) IntersectionWith(t Segment) Point {
+// IntersectionWith returns the intersection Point of the lines through the Segment and the other Segment
+//
+// It returns ErrParallel if the two are parallel
+func (s Segment) IntersectionWith(t Segment) (Point, error) {
 	sc, tc, dc := s.p.Cross(s.q), t.p.Cross(t.q), s.D().Cross(t.D())
-	return PointXY((sc*t.Dx()-tc*s.Dx())/dc, (sc*t.Dy()-tc*s.Dy())/dc)
+	if dc == 0 {
+		return Point{}, ErrParallel
+	}
+	return PointXY((sc*t.Dx()-tc*s.Dx())/dc, (sc*t.Dy()-tc*s.Dy())/dc), nil
 }
 
 // AngleBetween returns the angle between the Segment and the other Segment
@@ -132,10 +148,10 @@ func (s Segment) AngleBetween(t Segment) float64 {
 
 // Draw paints the Segment on the image with the given color
 //
-// It panics if the Segment is not well-defined
-func (s Segment) Draw(im *image.RGBA, c color.RGBA) {
-	if math.IsNaN(s.Length()) {
-		panic("the segment is not well-defined")
+// It returns ErrUndefinedSegment if the Segment is not well-defined
+func (s Segment) Draw(im *image.RGBA, c color.RGBA) error {
+	if l := s.Length(); math.IsNaN(l) || math.IsInf(l, 0) {
+		return ErrUndefinedSegment
 	}
 
 	dx, dy := s.Dx(), s.Dy()
@@ -163,4 +179,5 @@ func (s Segment) Draw(im *image.RGBA, c color.RGBA) {
 			)
 		}
 	}
+	return nil
 }
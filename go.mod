@@ -2,4 +2,7 @@ module screwSort
 
 go 1.18
 
-require golang.org/x/exp v0.0.0-20220722155223-a9213eeb770e
+require (
+	golang.org/x/exp v0.0.0-20220722155223-a9213eeb770e
+	golang.org/x/image v0.18.0
+)
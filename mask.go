@@ -1,13 +1,19 @@
 package main
 
 import (
+	"log"
 	"screwSort/vision"
 )
 
 func main() {
 	fn := "assets/data/single.png"
-	im := vision.ToGray(vision.OpenPng(fn))
-	im = vision.InverseThreshold(im, 150)
+	in, err := vision.OpenPng(fn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	im := vision.InverseThreshold(vision.ToGray(in), 150)
 	out := vision.ToRgba(im)
-	vision.SavePng(out, "assets/temp/out.png")
+	if err = vision.SavePng(out, "assets/temp/out.png"); err != nil {
+		log.Fatal(err)
+	}
 }
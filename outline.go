@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"screwSort/vision"
 	"strconv"
 )
@@ -15,15 +16,21 @@ func main() {
 	key := "length"
 
 	fn := fmt.Sprintf("assets/data/%s.png", key)
-	im := vision.ToGray(vision.OpenPng(fn))
+	in, err := vision.OpenPng(fn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	im := vision.ToGray(in)
 
-	for i, h := range vision.SuperHulls(im, data[key][0]) {
+	for i, h := range vision.SuperHulls(im, data[key][0], vision.AreaMin) {
 		p, _ := h.CenterPoint()
 		out := vision.ApplyAlpha(vision.ToRgba(vision.InverseThreshold(im, uint8(data[key][0]))), 0.3)
 		s := h.Simplify(data[key][1], data[key][2])
-		s.Draw(out, vision.Red, vision.Green)
+		_ = s.Draw(out, vision.Red, vision.Green)
 		p.Draw(out, vision.Cyan)
-		vision.SavePng(out, "assets/temp/out"+strconv.Itoa(i)+".png")
+		if err = vision.SavePng(out, "assets/temp/out"+strconv.Itoa(i)+".png"); err != nil {
+			log.Fatal(err)
+		}
 		fmt.Printf("CM: %s\nOutline points reduced from %d to %d\n\n", p, len(h.Ps()), len(s.Ps()))
 	}
 }
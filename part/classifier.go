@@ -0,0 +1,193 @@
+package part
+
+import (
+	"math"
+	"screwSort/calibration"
+	"screwSort/geometry"
+	"screwSort/vision"
+	"sort"
+)
+
+const (
+	// ScoreMin is the default score below which the best Candidate is rejected as an unknown object
+	ScoreMin = 0.3
+
+	maskLevel   = 127.5
+	maskPadding = 4
+)
+
+// The weights of the terms of Distance and of the size difference in Classify, whose score is e^-(weighted sum)
+const (
+	// the aspect and hole ratios separate screws, nuts, and washers most clearly, so they weigh twice
+	// as much as extent and circularity, which depend more on the smoothing of the outline
+	aspectWeight      = 10
+	holeWeight        = 10
+	extentWeight      = 5
+	circularityWeight = 5
+	// sizeWeight multiplies |log(l/pl)| + |log(w/pw)| for the measured and the catalog length and width,
+	// so that a part 10% larger in both loses a factor e^-1.9 ≈ 0.15 of its score and falls below ScoreMin
+	sizeWeight = 10
+)
+
+// Descriptor describes the rotation-invariant shape of a Region along with its principal extents
+type Descriptor struct {
+	length, width float64
+	aspect        float64
+	extent        float64
+	circularity   float64
+	holeRatio     float64
+}
+
+// Describe computes the Descriptor of the Region
+func Describe(r vision.Region) Descriptor {
+	o := r.Outer()
+	l, w := principalExtents(o)
+	a := o.Area()
+
+	d := Descriptor{length: l, width: w}
+	if l > 0 {
+		d.aspect = w / l
+		d.extent = a / (l * w)
+	}
+	if p := o.Perimeter(); p > 0 {
+		d.circularity = 4 * math.Pi * a / (p * p)
+	}
+	if a > 0 {
+		d.holeRatio = 1 - r.Area()/a
+	}
+	return d
+}
+
+// Length returns the extent of the Region along its major principal axis in the units of the Region
+func (d Descriptor) Length() float64 {
+	return d.length
+}
+
+// Width returns the extent of the Region along its minor principal axis in the units of the Region
+func (d Descriptor) Width() float64 {
+	return d.width
+}
+
+// Distance returns the weighted sum of the differences of the aspect ratio, extent, circularity, and hole ratio,
+// each in [0, 1], between the shapes of the Descriptor and another Descriptor
+//
+// It does not depend on size, so the Descriptors of parts with the same shape at different sizes are 0 apart
+func (d Descriptor) Distance(o Descriptor) float64 {
+	return aspectWeight*math.Abs(d.aspect-o.aspect) +
+		extentWeight*math.Abs(d.extent-o.extent) +
+		circularityWeight*math.Abs(d.circularity-o.circularity) +
+		holeWeight*math.Abs(d.holeRatio-o.holeRatio)
+}
+
+// Candidate describes a Part that a Region may be along with the score of the match in (0, 1]
+type Candidate struct {
+	part  Part
+	score float64
+}
+
+// Part returns the Part of the Candidate
+func (c Candidate) Part() Part {
+	return c.part
+}
+
+// Score returns the score of the Candidate
+func (c Candidate) Score() float64 {
+	return c.score
+}
+
+// Classifier matches Regions against the Descriptors of the masks of the catalog Parts
+type Classifier struct {
+	parts    []Part
+	ds       []Descriptor
+	cal      calibration.Calibration
+	scoreMin float64
+}
+
+// NewClassifier constructs a Classifier by extracting the outline of every catalog mask once
+//
+// The Calibration converts the classified Regions to millimetres so that their sizes are compared
+// with the dimensions of the Parts, or if it is uncalibrated only the shapes are compared
+func NewClassifier(cal calibration.Calibration, scoreMin float64) (Classifier, error) {
+	c := Classifier{cal: cal, scoreMin: scoreMin}
+	for _, p := range parts {
+		r, ok, err := maskRegion(p)
+		if err != nil {
+			return Classifier{}, err
+		}
+		if !ok {
+			continue
+		}
+		c.parts = append(c.parts, p)
+		c.ds = append(c.ds, Describe(r))
+	}
+	return c, nil
+}
+
+// maskRegion returns the largest Region of the mask of the Part in pixels and whether the mask has one
+func maskRegion(p Part) (vision.Region, bool, error) {
+	m, err := p.Mask()
+	if err != nil {
+		return vision.Region{}, false, err
+	}
+	im := vision.Pad(vision.ToGray(m), maskPadding, 255)
+	rs := vision.SuperHullRegions(im, maskLevel, vision.AreaMin)
+	if len(rs) == 0 {
+		return vision.Region{}, false, nil
+	}
+	r := rs[0]
+	for _, ri := range rs[1:] {
+		if ri.Area() > r.Area() {
+			r = ri
+		}
+	}
+	return r, true, nil
+}
+
+// Classify returns the Candidates for the Region ranked from best to worst
+// and whether the best one scores at least the minimum score
+//
+// A truncated Region shows only part of an object, so it has no Candidates and is never accepted
+func (c Classifier) Classify(r vision.Region) ([]Candidate, bool) {
+	if r.Truncated() {
+		return nil, false
+	}
+	d := Describe(c.cal.Region(r))
+	cs := make([]Candidate, len(c.parts))
+	for i, p := range c.parts {
+		e := d.Distance(c.ds[i])
+		if c.cal.IsCalibrated() {
+			pl, pw := math.Max(p.dx, p.dy), math.Min(p.dx, p.dy)
+			e += sizeWeight * (math.Abs(math.Log(d.length/pl)) + math.Abs(math.Log(d.width/pw)))
+		}
+		cs[i] = Candidate{p, math.Exp(-e)}
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].score > cs[j].score
+	})
+	return cs, len(cs) > 0 && cs[0].score >= c.scoreMin
+}
+
+// ClassifyAll classifies every Region and returns the best accepted Part of each
+// along with whether it was accepted
+func (c Classifier) ClassifyAll(rs []vision.Region) ([]Part, []bool) {
+	ps, oks := make([]Part, len(rs)), make([]bool, len(rs))
+	for i, r := range rs {
+		cs, ok := c.Classify(r)
+		if ok {
+			ps[i] = cs[0].part
+		}
+		oks[i] = ok
+	}
+	return ps, oks
+}
+
+func principalExtents(h vision.Hull) (float64, float64) {
+	c, theta := h.CenterPoint()
+	ps := make([]geometry.Point, len(h.Ps()))
+	for i, p := range h.Ps() {
+		ps[i] = p.RotateAbout(c, -theta)
+	}
+	pMin, pMax := vision.HullPs(ps).Bounds()
+	dx, dy := pMax.X()-pMin.X(), pMax.Y()-pMin.Y()
+	return math.Max(dx, dy), math.Min(dx, dy)
+}
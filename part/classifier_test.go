@@ -0,0 +1,81 @@
+package part
+
+import (
+	"screwSort/calibration"
+	"testing"
+)
+
+// maskResolution is the millimetres per pixel of the catalog masks
+const maskResolution = 0.01
+
+func TestClassifyMasks(t *testing.T) {
+	c, err := NewClassifier(calibration.CalibrationScale(maskResolution), ScoreMin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.parts) != len(parts) {
+		t.Fatalf("got %d of %d catalog masks", len(c.parts), len(parts))
+	}
+	for _, p := range parts {
+		r, ok, err := maskRegion(p)
+		if err != nil || !ok {
+			t.Errorf("%s: no Region in the mask: %v", p, err)
+			continue
+		}
+		cs, ok := c.Classify(r)
+		if !ok || cs[0].Part() != p {
+			t.Errorf("%s: classified as %s with score %v and acceptance %v", p, cs[0].Part(), cs[0].Score(), ok)
+		}
+	}
+}
+
+func TestClassifySizes(t *testing.T) {
+	// parts of the same shape that differ only in size, and the score of the other without calibration
+	tests := []struct {
+		name, a, b string
+		shape      float64
+	}{
+		{"washers", "98689A114", "98689A115", 0.9},
+		{"nuts", "91828A231", "91828A211", 0.85},
+		{"socket head screws", "92196A196", "91292A128", 0.9},
+	}
+	uncalibrated, err := NewClassifier(calibration.Calibration{}, ScoreMin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calibrated, err := NewClassifier(calibration.CalibrationScale(maskResolution), ScoreMin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byId := make(map[string]Part)
+	for _, p := range parts {
+		byId[p.id] = p
+	}
+	// score returns the score of the Candidate Part b for the mask of Part a
+	score := func(c Classifier, a, b Part) float64 {
+		r, _, err := maskRegion(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs, _ := c.Classify(r)
+		for _, cd := range cs {
+			if cd.Part() == b {
+				return cd.Score()
+			}
+		}
+		return 0
+	}
+	for _, tt := range tests {
+		a, b := byId[tt.a], byId[tt.b]
+		for _, pair := range [][2]Part{{a, b}, {b, a}} {
+			// shapes alone cannot tell the parts apart
+			if s := score(uncalibrated, pair[0], pair[1]); s < tt.shape {
+				t.Errorf("%s: uncalibrated score of %s for %s = %v, want at least %v", tt.name, pair[1], pair[0], s, tt.shape)
+			}
+			// but their sizes in millimetres can
+			if s := score(calibrated, pair[0], pair[1]); s >= ScoreMin {
+				t.Errorf("%s: calibrated score of %s for %s = %v, want below %v", tt.name, pair[1], pair[0], s, ScoreMin)
+			}
+		}
+	}
+}
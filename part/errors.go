@@ -0,0 +1,14 @@
+package part
+
+import "errors"
+
+var (
+	// ErrNoShank is returned when a Hull has no part narrower than its head long enough to measure a thread on
+	ErrNoShank = errors.New("part: hull has no shank")
+	// ErrNoThread is returned when the flanks of a shank do not repeat over several pitches at a plausible depth
+	ErrNoThread = errors.New("part: shank flanks are not periodic")
+	// ErrUnknownThread is returned when a measured thread fits no standard ThreadSize within its tolerances
+	ErrUnknownThread = errors.New("part: thread matches no standard size")
+	// ErrAmbiguousThread is returned when a measured thread fits both a metric and a Unified ThreadSize
+	ErrAmbiguousThread = errors.New("part: thread matches both metric and unified sizes")
+)
@@ -1,6 +1,7 @@
 package part
 
 import (
+	"fmt"
 	"image"
 	"path"
 	"runtime"
@@ -14,9 +15,41 @@ type Part struct {
 	id     string
 }
 
+// Name returns the name of the Part
+func (p Part) Name() string {
+	return p.name
+}
+
+// Id returns the McMaster-Carr id of the Part
+func (p Part) Id() string {
+	return p.id
+}
+
+// Dx returns the width of the cross-section of the Part in millimetres
+func (p Part) Dx() float64 {
+	return p.dx
+}
+
+// Dy returns the height of the cross-section of the Part in millimetres
+func (p Part) Dy() float64 {
+	return p.dy
+}
+
+// String returns a string representation of the Part
+func (p Part) String() string {
+	return fmt.Sprintf("%s (%s)", p.name, p.id)
+}
+
+// Parts returns the catalog of known Parts
+func Parts() []Part {
+	out := make([]Part, len(parts))
+	copy(out, parts)
+	return out
+}
+
 // Mask loads and returns the PNG mask of the Part
-func (p Part) Mask() image.Image {
-	_, fn, _, _ := runtime.Caller(1)
+func (p Part) Mask() (image.Image, error) {
+	_, fn, _, _ := runtime.Caller(0)
 	fp := path.Join(path.Dir(fn), "masks", p.id+".png")
 	return vision.OpenPng(fp)
 }
@@ -0,0 +1,419 @@
+package part
+
+import (
+	"fmt"
+	"math"
+	"screwSort/geometry"
+	"screwSort/vision"
+	"sort"
+)
+
+const (
+	// profileSamples is the number of samples of the flank profiles along the oriented bounding box of a screw
+	profileSamples = 1024
+	// shankTrim is the fraction of the shank trimmed from each end to skip the chamfer and the fillet under the head
+	shankTrim = 0.1
+	// pitchCorrelationMin is the autocorrelation below which the flanks are not considered periodic
+	pitchCorrelationMin = 0.3
+	// pitchesMin is the number of pitches that must fit along the shank for the flanks to be considered a thread
+	pitchesMin = 3
+	// depthMin and depthMax bound the depth of a thread from crest to root as a fraction of its pitch,
+	// which is about 0.61 for 60° threads
+	depthMin, depthMax = 0.3, 0.9
+	// pitchTolerance is the relative difference from the pitch of a ThreadSize within which a measured pitch fits it
+	pitchTolerance = 0.04
+	// majorMargin is the measurement error in millimetres allowed beyond the major diameter range of a ThreadSize
+	majorMargin = 0.05
+)
+
+// ThreadSeries identifies a family of standard threads
+type ThreadSeries uint8
+
+const (
+	ThreadMetric ThreadSeries = iota
+	ThreadUNC
+	ThreadUNF
+)
+
+// String returns the name of the ThreadSeries
+func (s ThreadSeries) String() string {
+	return [...]string{"Metric", "UNC", "UNF"}[s]
+}
+
+// ThreadSize describes a standard thread by its designation, series, nominal major diameter, pitch,
+// and the range of the major diameter of an external thread of class 6g or 2A in millimetres
+type ThreadSize struct {
+	name               string
+	series             ThreadSeries
+	major              float64
+	pitch              float64
+	majorMin, majorMax float64
+}
+
+// Name returns the designation of the ThreadSize
+func (t ThreadSize) Name() string {
+	return t.name
+}
+
+// Series returns the ThreadSeries of the ThreadSize
+func (t ThreadSize) Series() ThreadSeries {
+	return t.series
+}
+
+// Major returns the nominal major diameter of the ThreadSize in millimetres
+func (t ThreadSize) Major() float64 {
+	return t.major
+}
+
+// Pitch returns the pitch of the ThreadSize in millimetres
+func (t ThreadSize) Pitch() float64 {
+	return t.pitch
+}
+
+// MajorRange returns the smallest and largest major diameters of a screw of the ThreadSize in millimetres
+func (t ThreadSize) MajorRange() (float64, float64) {
+	return t.majorMin, t.majorMax
+}
+
+// fits returns whether the measured pitch and major diameter are within the tolerances of the ThreadSize
+func (t ThreadSize) fits(pitch, major float64) bool {
+	return math.Abs(pitch/t.pitch-1) <= pitchTolerance &&
+		major >= t.majorMin-majorMargin && major <= t.majorMax+majorMargin
+}
+
+// IsMetric returns whether the ThreadSize is metric rather than Unified
+func (t ThreadSize) IsMetric() bool {
+	return t.series == ThreadMetric
+}
+
+// String returns a string representation of the ThreadSize
+func (t ThreadSize) String() string {
+	return fmt.Sprintf("%s (%s)", t.name, t.series)
+}
+
+// ThreadSizes returns the catalog of standard ThreadSizes that measured threads are matched against
+func ThreadSizes() []ThreadSize {
+	out := make([]ThreadSize, len(threadSizes))
+	copy(out, threadSizes)
+	return out
+}
+
+// inch is the number of millimetres in an inch
+const inch = 25.4
+
+// threadSizes lists the coarse metric threads with the major diameters of ISO 965 class 6g
+// and the Unified threads with those of ASME B1.1 class 2A
+var threadSizes = []ThreadSize{
+	{"M2", ThreadMetric, 2, 0.4, 1.886, 1.981},
+	{"M2.5", ThreadMetric, 2.5, 0.45, 2.380, 2.480},
+	{"M3", ThreadMetric, 3, 0.5, 2.874, 2.980},
+	{"M4", ThreadMetric, 4, 0.7, 3.838, 3.978},
+	{"M5", ThreadMetric, 5, 0.8, 4.826, 4.976},
+	{"M6", ThreadMetric, 6, 1, 5.794, 5.974},
+	{"M8", ThreadMetric, 8, 1.25, 7.760, 7.972},
+	{"2-56", ThreadUNC, 0.086 * inch, inch / 56, 0.0803 * inch, 0.0850 * inch},
+	{"4-40", ThreadUNC, 0.112 * inch, inch / 40, 0.1050 * inch, 0.1110 * inch},
+	{"6-32", ThreadUNC, 0.138 * inch, inch / 32, 0.1299 * inch, 0.1370 * inch},
+	{"8-32", ThreadUNC, 0.164 * inch, inch / 32, 0.1559 * inch, 0.1630 * inch},
+	{"10-24", ThreadUNC, 0.19 * inch, inch / 24, 0.1818 * inch, 0.1890 * inch},
+	{"1/4-20", ThreadUNC, 0.25 * inch, inch / 20, 0.2408 * inch, 0.2489 * inch},
+	{"4-48", ThreadUNF, 0.112 * inch, inch / 48, 0.1055 * inch, 0.1110 * inch},
+	{"6-40", ThreadUNF, 0.138 * inch, inch / 40, 0.1310 * inch, 0.1370 * inch},
+	{"8-36", ThreadUNF, 0.164 * inch, inch / 36, 0.1566 * inch, 0.1630 * inch},
+	{"10-32", ThreadUNF, 0.19 * inch, inch / 32, 0.1825 * inch, 0.1890 * inch},
+	{"1/4-28", ThreadUNF, 0.25 * inch, inch / 28, 0.2425 * inch, 0.2490 * inch},
+}
+
+// Thread describes the thread measured on the shank of a screw along with the standard ThreadSize it fits
+type Thread struct {
+	pitch        float64
+	major, minor float64
+	shank        geometry.Segment
+	size         ThreadSize
+}
+
+// Pitch returns the measured distance between neighboring crests of the Thread
+func (t Thread) Pitch() float64 {
+	return t.pitch
+}
+
+// Major returns the measured diameter across the crests of the Thread
+func (t Thread) Major() float64 {
+	return t.major
+}
+
+// Minor returns the measured diameter across the roots of the Thread
+func (t Thread) Minor() float64 {
+	return t.minor
+}
+
+// Shank returns the Segment along the axis of the screw over which the Thread was measured
+func (t Thread) Shank() geometry.Segment {
+	return t.shank
+}
+
+// Size returns the standard ThreadSize that the measured pitch and major diameter fit,
+// or the zero ThreadSize if they fit none or are ambiguous
+func (t Thread) Size() ThreadSize {
+	return t.size
+}
+
+// String returns a string representation of the Thread
+func (t Thread) String() string {
+	return fmt.Sprintf("Thread{%s, pitch %.3f, major %.3f, minor %.3f}", t.size, t.pitch, t.major, t.minor)
+}
+
+// AnalyzeThread measures the Thread on the shank of the Hull of a screw in millimetres,
+// such as one converted with Calibration.Hull
+//
+// The Hull is sampled across the long axis of its oriented bounding box into the two flank profiles,
+// the shank is the longest run narrower than the head, and the pitch is the lag of the first peak
+// of the autocorrelation of the flanks
+//
+// It returns ErrNoThread unless at least three pitches fit along the shank at a depth plausible for the pitch,
+// and the measured Thread with ErrUnknownThread or ErrAmbiguousThread if it does not fit exactly one kind of ThreadSize
+func AnalyzeThread(h vision.Hull) (Thread, error) {
+	r := h.MinAreaRect()
+	angle := r.Angle()
+	if r.Height() > r.Width() {
+		angle += math.Pi / 2
+	}
+	c := r.Center()
+	ps := make([]geometry.Point, len(h.Ps()))
+	for i, p := range h.Ps() {
+		ps[i] = p.RotateAbout(c, -angle)
+	}
+	tops, bottoms, step, x0 := flankProfiles(ps, r.Length())
+
+	widths := make([]float64, len(tops))
+	for i := range tops {
+		widths[i] = bottoms[i] - tops[i]
+	}
+	i0, i1 := shankRange(widths)
+	trim := int(shankTrim * float64(i1-i0))
+	i0, i1 = i0+trim, i1-trim
+	if i1-i0 < 8 {
+		return Thread{}, ErrNoShank
+	}
+	tops, bottoms = tops[i0:i1], bottoms[i0:i1]
+
+	// measure the flanks from the axis of the shank, which need not be exactly along the bounding box,
+	// so distances along the profile stretch and distances across it shrink by the tilt of the axis
+	mids := make([]float64, len(tops))
+	for i := range tops {
+		mids[i] = (tops[i] + bottoms[i]) / 2
+	}
+	a, b := linearTrend(mids)
+	along := math.Hypot(step, b)
+	across := step / along
+	rTops, rBottoms := make([]float64, len(tops)), make([]float64, len(tops))
+	for i := range tops {
+		m := a + b*float64(i)
+		rTops[i], rBottoms[i] = (m-tops[i])*across, (bottoms[i]-m)*across
+	}
+
+	lt, ct := profilePeriod(rTops)
+	lb, cb := profilePeriod(rBottoms)
+	if ct < pitchCorrelationMin && cb < pitchCorrelationMin {
+		return Thread{}, ErrNoThread
+	}
+	lag := lt
+	if ct < pitchCorrelationMin {
+		lag = lb
+	} else if cb >= pitchCorrelationMin {
+		lag = (lt + lb) / 2
+	}
+	if lag*pitchesMin > float64(len(tops)) {
+		return Thread{}, ErrNoThread
+	}
+
+	t := Thread{
+		pitch: lag * along,
+		major: periodExtreme(rTops, lag, math.Max) + periodExtreme(rBottoms, lag, math.Max),
+		minor: periodExtreme(rTops, lag, math.Min) + periodExtreme(rBottoms, lag, math.Min),
+	}
+	if depth := (t.major - t.minor) / 2; depth < depthMin*t.pitch || depth > depthMax*t.pitch {
+		return Thread{}, ErrNoThread
+	}
+	axis := func(i int) geometry.Point {
+		return geometry.PointXY(x0+float64(i0+i)*step, a+b*float64(i)).RotateAbout(c, angle)
+	}
+	t.shank = geometry.SegmentPQ(axis(0), axis(len(tops)-1))
+	size, err := matchThreadSize(t.pitch, t.major)
+	t.size = size
+	return t, err
+}
+
+// periodExtreme returns the median over every whole period of the profile of its extreme within the period,
+// which finds the crests or roots of a thread without being pulled towards its flanks
+func periodExtreme(vs []float64, period float64, extreme func(float64, float64) float64) float64 {
+	var es []float64
+	for k := 0; float64(k+1)*period <= float64(len(vs)); k++ {
+		e := vs[int(float64(k)*period)]
+		for i := int(float64(k) * period); i < int(float64(k+1)*period); i++ {
+			e = extreme(e, vs[i])
+		}
+		es = append(es, e)
+	}
+	if len(es) == 0 {
+		return 0
+	}
+	return percentile(es, 0.5)
+}
+
+// flankProfiles returns the smallest and largest y of the outline at evenly spaced x across the points,
+// which span the length along x about their center, along with the spacing and the first x
+func flankProfiles(ps []geometry.Point, length float64) ([]float64, []float64, float64, float64) {
+	pMin, pMax := vision.HullPs(ps).Bounds()
+	step := math.Max(pMax.X()-pMin.X(), length) / profileSamples
+	x0 := pMin.X() + step/2
+	tops, bottoms := make([]float64, profileSamples), make([]float64, profileSamples)
+	for i := range tops {
+		tops[i], bottoms[i] = math.Inf(1), math.Inf(-1)
+	}
+	n := len(ps)
+	for j, p := range ps {
+		q := ps[(j+1)%n]
+		lo, hi := math.Min(p.X(), q.X()), math.Max(p.X(), q.X())
+		for i := int(math.Ceil((lo - x0) / step)); i < profileSamples && x0+float64(i)*step <= hi; i++ {
+			if i < 0 || hi == lo {
+				continue
+			}
+			x := x0 + float64(i)*step
+			y := p.Y() + (x-p.X())*(q.Y()-p.Y())/(q.X()-p.X())
+			tops[i], bottoms[i] = math.Min(tops[i], y), math.Max(bottoms[i], y)
+		}
+	}
+	// samples that the outline does not cross take their nearest crossed neighbor
+	for i := range tops {
+		if math.IsInf(tops[i], 1) && i > 0 {
+			tops[i], bottoms[i] = tops[i-1], bottoms[i-1]
+		}
+	}
+	for i := len(tops) - 2; i >= 0; i-- {
+		if math.IsInf(tops[i], 1) {
+			tops[i], bottoms[i] = tops[i+1], bottoms[i+1]
+		}
+	}
+	return tops, bottoms, step, x0
+}
+
+// shankRange returns the longest run {i0, ..., i1-1} of widths below halfway between the median width
+// and the width of the head, which is the largest
+func shankRange(widths []float64) (int, int) {
+	wMax := 0.
+	for _, w := range widths {
+		wMax = math.Max(wMax, w)
+	}
+	t := (percentile(widths, 0.5) + wMax) / 2
+	i0, i1 := 0, 0
+	for i := 0; i < len(widths); {
+		if widths[i] <= 0 || widths[i] >= t {
+			i++
+			continue
+		}
+		j := i
+		for j < len(widths) && widths[j] > 0 && widths[j] < t {
+			j++
+		}
+		if j-i > i1-i0 {
+			i0, i1 = i, j
+		}
+		i = j
+	}
+	return i0, i1
+}
+
+// profilePeriod returns the lag in samples of the first peak of the autocorrelation of the detrended profile
+// past its first zero crossing, refined by a parabola, along with the correlation there
+func profilePeriod(vs []float64) (float64, float64) {
+	n := len(vs)
+	a, b := linearTrend(vs)
+	ds := make([]float64, n)
+	for i, v := range vs {
+		ds[i] = v - a - b*float64(i)
+	}
+	ac := make([]float64, n/2)
+	for lag := range ac {
+		s := 0.
+		for i := 0; i+lag < n; i++ {
+			s += ds[i] * ds[i+lag]
+		}
+		ac[lag] = s / float64(n-lag)
+	}
+	if ac[0] == 0 {
+		return 0, 0
+	}
+	k := 1
+	for k < len(ac) && ac[k] > 0 {
+		k++
+	}
+	best := -1
+	for ; k < len(ac)-1; k++ {
+		if ac[k] > 0 && ac[k] >= ac[k-1] && ac[k] >= ac[k+1] && (best < 0 || ac[k] > ac[best]) {
+			best = k
+		} else if best >= 0 && ac[k] < 0 {
+			break
+		}
+	}
+	if best < 0 {
+		return 0, 0
+	}
+	t := 0.
+	if d := ac[best-1] - 2*ac[best] + ac[best+1]; d < 0 {
+		t = (ac[best-1] - ac[best+1]) / (2 * d)
+	}
+	return float64(best) + t, ac[best] / ac[0]
+}
+
+// linearTrend returns the intercept and slope of the least-squares line through the values against their indices
+func linearTrend(vs []float64) (float64, float64) {
+	n := float64(len(vs))
+	var sx, sy, sxx, sxy float64
+	for i, v := range vs {
+		x := float64(i)
+		sx, sy, sxx, sxy = sx+x, sy+v, sxx+x*x, sxy+x*v
+	}
+	d := n*sxx - sx*sx
+	if d == 0 {
+		return sy / n, 0
+	}
+	b := (n*sxy - sx*sy) / d
+	return (sy - b*sx) / n, b
+}
+
+func percentile(vs []float64, f float64) float64 {
+	s := append([]float64(nil), vs...)
+	sort.Float64s(s)
+	return s[int(f*float64(len(s)-1))]
+}
+
+// matchThreadSize returns the ThreadSize that the measured pitch and major diameter fit within the tolerances of,
+// or ErrAmbiguousThread if both a metric and a Unified ThreadSize fit and ErrUnknownThread if none does
+//
+// Among fitting ThreadSizes of one kind, the one with the relatively closest pitch is returned
+func matchThreadSize(pitch, major float64) (ThreadSize, error) {
+	var best ThreadSize
+	metric, unified := false, false
+	eMin := math.Inf(1)
+	for _, s := range threadSizes {
+		if !s.fits(pitch, major) {
+			continue
+		}
+		if s.IsMetric() {
+			metric = true
+		} else {
+			unified = true
+		}
+		if e := math.Abs(math.Log(pitch / s.pitch)); e < eMin {
+			best, eMin = s, e
+		}
+	}
+	switch {
+	case metric && unified:
+		return ThreadSize{}, ErrAmbiguousThread
+	case !metric && !unified:
+		return ThreadSize{}, ErrUnknownThread
+	}
+	return best, nil
+}
@@ -0,0 +1,154 @@
+package part
+
+import (
+	"errors"
+	"math"
+	"screwSort/calibration"
+	"screwSort/geometry"
+	"screwSort/vision"
+	"testing"
+)
+
+// screwHull returns the outline of a socket head screw along +x with a sharp 60° thread of the major diameter
+// and pitch on a shank of the length, rotated by the angle about the origin
+func screwHull(major, pitch, length, angle float64) vision.Hull {
+	minor := major - 1.2269*pitch
+	head := major * 1.6
+	var tops, bottoms []geometry.Point
+	tops = append(tops, geometry.PointXY(-0.6*head, -head/2), geometry.PointXY(0, -head/2))
+	bottoms = append(bottoms, geometry.PointXY(-0.6*head, head/2), geometry.PointXY(0, head/2))
+	for x := 0.; x+pitch <= length; x += pitch {
+		tops = append(tops, geometry.PointXY(x, -major/2), geometry.PointXY(x+pitch/2, -minor/2))
+		// the thread advances by half a pitch from one flank to the other
+		bottoms = append(bottoms, geometry.PointXY(x+pitch/2, major/2), geometry.PointXY(x+pitch, minor/2))
+	}
+	ps := tops
+	for i := len(bottoms) - 1; i >= 0; i-- {
+		ps = append(ps, bottoms[i])
+	}
+	for i, p := range ps {
+		ps[i] = p.RotateAbout(geometry.PointXY(0, 0), angle).Translate(30, 20)
+	}
+	return vision.HullPs(ps)
+}
+
+func TestAnalyzeThread(t *testing.T) {
+	tests := []struct {
+		name         string
+		major, pitch float64
+		want         string
+	}{
+		{"M5", 4.9, 0.8, "M5"},
+		{"10-32", 4.72, inch / 32, "10-32"},
+		{"M4", 3.9, 0.7, "M4"},
+		{"8-32", 4.08, inch / 32, "8-32"},
+	}
+	for _, tt := range tests {
+		for _, angle := range []float64{0, 0.4, 1.3, 2.9} {
+			th, err := AnalyzeThread(screwHull(tt.major, tt.pitch, 16, angle))
+			if err != nil {
+				t.Errorf("%s at %.1f: %v", tt.name, angle, err)
+				continue
+			}
+			if got := th.Size().Name(); got != tt.want {
+				t.Errorf("%s at %.1f: got %s (%v), want %s", tt.name, angle, got, th, tt.want)
+			}
+			if math.Abs(th.Pitch()-tt.pitch) > 0.01 {
+				t.Errorf("%s at %.1f: pitch %.3f, want %.3f", tt.name, angle, th.Pitch(), tt.pitch)
+			}
+			if math.Abs(th.Major()-tt.major) > 0.03 {
+				t.Errorf("%s at %.1f: major %.3f, want %.3f", tt.name, angle, th.Major(), tt.major)
+			}
+		}
+	}
+}
+
+// threadResolution is the resolution of assets/data/thread.png in pixels per millimetre, between those given by
+// the socket head diameters and the overall lengths of its screws
+const threadResolution = 31.8
+
+func TestAnalyzeThreadImage(t *testing.T) {
+	in, err := vision.OpenPng("../assets/data/thread.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	im := vision.ToGray(in)
+	// the backlight falls off across the image, so each pixel is compared against its neighborhood
+	s, err := vision.SauvolaSurface(im, 200, 0.2, 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs, err := vision.SurfaceSuperHulls(im, s, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cal := calibration.CalibrationScale(1 / threadResolution)
+
+	// three M4 14 mm screws, 18 mm long with the head, and three 8-32 5/8 in screws, 20 mm long with the head
+	counts := make(map[string]int)
+	for _, h := range hs {
+		if h.Truncated() {
+			continue
+		}
+		h = cal.Hull(h)
+		want, pitch := "M4", 0.7
+		if h.MinAreaRect().Length() > 19 {
+			want, pitch = "8-32", inch/32
+		}
+		counts[want]++
+		th, err := AnalyzeThread(h)
+		if err != nil {
+			t.Errorf("%s at %v: %v", want, h.Centroid(), err)
+			continue
+		}
+		if got := th.Size().Name(); got != want {
+			t.Errorf("%s at %v: got %s (%v)", want, h.Centroid(), got, th)
+		}
+		if math.Abs(th.Pitch()/pitch-1) > pitchTolerance {
+			t.Errorf("%s at %v: pitch %.3f, want %.3f", want, h.Centroid(), th.Pitch(), pitch)
+		}
+	}
+	if counts["M4"] != 3 || counts["8-32"] != 3 {
+		t.Errorf("found %d M4 and %d 8-32 screws, want 3 of each", counts["M4"], counts["8-32"])
+	}
+}
+
+func TestAnalyzeThreadRejectsSmoothOutlines(t *testing.T) {
+	tests := []struct {
+		name string
+		ps   []geometry.Point
+	}{
+		{"triangle", []geometry.Point{geometry.PointXY(0, 0), geometry.PointXY(10, 0), geometry.PointXY(5, 8)}},
+		{"plain shank", []geometry.Point{
+			geometry.PointXY(-4, -4), geometry.PointXY(0, -4), geometry.PointXY(0, -2), geometry.PointXY(16, -2),
+			geometry.PointXY(16, 2), geometry.PointXY(0, 2), geometry.PointXY(0, 4), geometry.PointXY(-4, 4),
+		}},
+	}
+	for _, tt := range tests {
+		_, err := AnalyzeThread(vision.HullPs(tt.ps))
+		if !errors.Is(err, ErrNoThread) && !errors.Is(err, ErrNoShank) {
+			t.Errorf("%s: got %v, want ErrNoThread or ErrNoShank", tt.name, err)
+		}
+	}
+}
+
+func TestMatchThreadSize(t *testing.T) {
+	tests := []struct {
+		pitch, major float64
+		want         string
+		err          error
+	}{
+		{0.797, 4.89, "M5", nil},
+		{0.797, 4.70, "10-32", nil},
+		{0.797, 4.80, "", ErrAmbiguousThread},
+		{0.70, 3.90, "M4", nil},
+		{0.79, 4.05, "8-32", nil},
+		{0.60, 4.50, "", ErrUnknownThread},
+	}
+	for _, tt := range tests {
+		s, err := matchThreadSize(tt.pitch, tt.major)
+		if err != tt.err || s.Name() != tt.want {
+			t.Errorf("matchThreadSize(%v, %v) = %s, %v, want %s, %v", tt.pitch, tt.major, s.Name(), err, tt.want, tt.err)
+		}
+	}
+}
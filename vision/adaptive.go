@@ -0,0 +1,206 @@
+package vision
+
+import (
+	"image"
+	"math"
+)
+
+// Integral describes the summed-area tables of the values and the squared values of an image,
+// which allow the sum over any rectangular window to be found in constant time
+type Integral struct {
+	rect   image.Rectangle
+	s, s2  []float64
+	stride int
+}
+
+// IntegralImage constructs the Integral of the image
+func IntegralImage(im *image.Gray) Integral {
+	return integralValues(im.Rect, grayValues(im))
+}
+
+func integralValues(rect image.Rectangle, vs []float64) Integral {
+	dx, dy := rect.Dx(), rect.Dy()
+	stride := dx + 1
+	s, s2 := make([]float64, stride*(dy+1)), make([]float64, stride*(dy+1))
+	for y := 0; y < dy; y++ {
+		var r, r2 float64
+		for x := 0; x < dx; x++ {
+			v := vs[y*dx+x]
+			r += v
+			r2 += v * v
+			s[(y+1)*stride+x+1] = s[y*stride+x+1] + r
+			s2[(y+1)*stride+x+1] = s2[y*stride+x+1] + r2
+		}
+	}
+	return Integral{rect, s, s2, stride}
+}
+
+// Sum returns the sum of the values and the sum of the squared values inside the window,
+// which is clipped to the bounds of the image
+func (ii Integral) Sum(r image.Rectangle) (float64, float64, int) {
+	r = r.Intersect(ii.rect)
+	if r.Empty() {
+		return 0, 0, 0
+	}
+	x0, y0 := r.Min.X-ii.rect.Min.X, r.Min.Y-ii.rect.Min.Y
+	x1, y1 := r.Max.X-ii.rect.Min.X, r.Max.Y-ii.rect.Min.Y
+	i00, i01, i10, i11 := y0*ii.stride+x0, y0*ii.stride+x1, y1*ii.stride+x0, y1*ii.stride+x1
+	return ii.s[i11] - ii.s[i01] - ii.s[i10] + ii.s[i00], ii.s2[i11] - ii.s2[i01] - ii.s2[i10] + ii.s2[i00], r.Dx() * r.Dy()
+}
+
+// Stats returns the mean and standard deviation of the values in the (2r+1)×(2r+1) window
+// centered at the pixel
+func (ii Integral) Stats(x, y, r int) (float64, float64) {
+	s, s2, n := ii.Sum(image.Rect(x-r, y-r, x+r+1, y+r+1))
+	if n == 0 {
+		return 0, 0
+	}
+	m := s / float64(n)
+	return m, math.Sqrt(math.Max(s2/float64(n)-m*m, 0))
+}
+
+// Surface describes a real value for every pixel of an image, such as a threshold or a distance
+type Surface struct {
+	rect image.Rectangle
+	vs   []float64
+}
+
+// ConstantSurface constructs a Surface with the same value everywhere
+func ConstantSurface(rect image.Rectangle, v float64) Surface {
+	vs := make([]float64, rect.Dx()*rect.Dy())
+	for i := range vs {
+		vs[i] = v
+	}
+	return Surface{rect, vs}
+}
+
+// Bounds returns the bounds of the Surface
+func (s Surface) Bounds() image.Rectangle {
+	return s.rect
+}
+
+// At returns the value at the pixel
+func (s Surface) At(x, y int) float64 {
+	return s.vs[(y-s.rect.Min.Y)*s.rect.Dx()+x-s.rect.Min.X]
+}
+
+// MeanSurface returns the Surface of local means over (2r+1)×(2r+1) windows offset by c
+//
+// It returns ErrInvalidRadius if r is not positive
+func MeanSurface(im *image.Gray, r int, c float64, ws ...Workers) (Surface, error) {
+	if r <= 0 {
+		return Surface{}, ErrInvalidRadius
+	}
+	ii := IntegralImage(im)
+	return statsSurface(im.Rect, func(x, y int) float64 {
+		m, _ := ii.Stats(x, y, r)
+		return m - c
+	}, workersOf(ws)), nil
+}
+
+// GaussianSurface returns the Surface of Gaussian-weighted local means with standard deviation
+// sigma offset by c
+//
+// The Gaussian is approximated by three successive box means each found with an Integral.
+// It returns ErrInvalidSigma if sigma is not positive
+func GaussianSurface(im *image.Gray, sigma, c float64, ws ...Workers) (Surface, error) {
+	if !(sigma > 0) {
+		return Surface{}, ErrInvalidSigma
+	}
+	r := int(math.Floor((math.Sqrt(4*sigma*sigma+1) - 1) / 2))
+	if r < 1 {
+		r = 1
+	}
+	vs := grayValues(im)
+	for k := 0; k < 3; k++ {
+		ii := integralValues(im.Rect, vs)
+		vs = statsSurface(im.Rect, func(x, y int) float64 {
+			m, _ := ii.Stats(x, y, r)
+			return m
+		}, workersOf(ws)).vs
+	}
+	for i := range vs {
+		vs[i] -= c
+	}
+	return Surface{im.Rect, vs}, nil
+}
+
+// NiblackSurface returns the Surface m + k·s where m and s are the local mean and standard deviation
+// over (2r+1)×(2r+1) windows
+//
+// It returns ErrInvalidRadius if r is not positive
+func NiblackSurface(im *image.Gray, r int, k float64, ws ...Workers) (Surface, error) {
+	if r <= 0 {
+		return Surface{}, ErrInvalidRadius
+	}
+	ii := IntegralImage(im)
+	return statsSurface(im.Rect, func(x, y int) float64 {
+		m, s := ii.Stats(x, y, r)
+		return m + k*s
+	}, workersOf(ws)), nil
+}
+
+// SauvolaSurface returns the Surface m·(1 + k·(s/sr - 1)) where m and s are the local mean and standard
+// deviation over (2r+1)×(2r+1) windows and sr is the dynamic range of the standard deviation
+//
+// It returns ErrInvalidRadius if r is not positive and ErrInvalidRange if sr is not positive
+func SauvolaSurface(im *image.Gray, r int, k, sr float64, ws ...Workers) (Surface, error) {
+	if r <= 0 {
+		return Surface{}, ErrInvalidRadius
+	}
+	if !(sr > 0) {
+		return Surface{}, ErrInvalidRange
+	}
+	ii := IntegralImage(im)
+	return statsSurface(im.Rect, func(x, y int) float64 {
+		m, s := ii.Stats(x, y, r)
+		return m * (1 + k*(s/sr-1))
+	}, workersOf(ws)), nil
+}
+
+// SurfaceThreshold returns the binary image that is white where the pixel is at least the Surface
+//
+// It returns ErrBoundsMismatch if the Surface was not made for the bounds of the image
+func SurfaceThreshold(im *image.Gray, s Surface, ws ...Workers) (*image.Gray, error) {
+	return surfaceThreshold(im, s, false, workersOf(ws))
+}
+
+// InverseSurfaceThreshold returns the binary image that is white where the pixel is at most the Surface
+//
+// It returns ErrBoundsMismatch if the Surface was not made for the bounds of the image
+func InverseSurfaceThreshold(im *image.Gray, s Surface, ws ...Workers) (*image.Gray, error) {
+	return surfaceThreshold(im, s, true, workersOf(ws))
+}
+
+func surfaceThreshold(im *image.Gray, s Surface, inverse bool, ws Workers) (*image.Gray, error) {
+	if s.rect != im.Rect {
+		return nil, ErrBoundsMismatch
+	}
+	out := image.NewGray(im.Rect)
+	dx := im.Rect.Dx()
+	ws.parallelRows(im.Rect.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row, ts := im.Pix[y*im.Stride:y*im.Stride+dx], s.vs[y*dx:(y+1)*dx]
+			for x, v := range row {
+				if t := ts[x]; inverse && float64(v) <= t || !inverse && float64(v) >= t {
+					out.Pix[y*out.Stride+x] = w
+				}
+			}
+		}
+	})
+	return out, nil
+}
+
+func statsSurface(rect image.Rectangle, f func(x, y int) float64, ws Workers) Surface {
+	vs := make([]float64, rect.Dx()*rect.Dy())
+	ws.parallelRows(rect.Dy(), func(y0, y1 int) {
+		i := y0 * rect.Dx()
+		for y := rect.Min.Y + y0; y < rect.Min.Y+y1; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				vs[i] = f(x, y)
+				i++
+			}
+		}
+	})
+	return Surface{rect, vs}
+}
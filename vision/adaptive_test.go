@@ -0,0 +1,104 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestSurfacesInvalid(t *testing.T) {
+	im := image.NewGray(image.Rect(0, 0, 8, 8))
+	tests := []struct {
+		name string
+		f    func() (Surface, error)
+		want error
+	}{
+		{"MeanSurface", func() (Surface, error) { return MeanSurface(im, 3, 0) }, nil},
+		{"MeanSurface with zero radius", func() (Surface, error) { return MeanSurface(im, 0, 0) }, ErrInvalidRadius},
+		{"MeanSurface with negative radius", func() (Surface, error) { return MeanSurface(im, -2, 0) }, ErrInvalidRadius},
+		{"GaussianSurface", func() (Surface, error) { return GaussianSurface(im, 2, 0) }, nil},
+		{"GaussianSurface with zero sigma", func() (Surface, error) { return GaussianSurface(im, 0, 0) }, ErrInvalidSigma},
+		{"GaussianSurface with NaN sigma", func() (Surface, error) { return GaussianSurface(im, math.NaN(), 0) }, ErrInvalidSigma},
+		{"NiblackSurface", func() (Surface, error) { return NiblackSurface(im, 3, -0.2) }, nil},
+		{"NiblackSurface with zero radius", func() (Surface, error) { return NiblackSurface(im, 0, -0.2) }, ErrInvalidRadius},
+		{"SauvolaSurface", func() (Surface, error) { return SauvolaSurface(im, 3, 0.2, 128) }, nil},
+		{"SauvolaSurface with zero radius", func() (Surface, error) { return SauvolaSurface(im, 0, 0.2, 128) }, ErrInvalidRadius},
+		{"SauvolaSurface with negative radius", func() (Surface, error) { return SauvolaSurface(im, -2, 0.2, 128) }, ErrInvalidRadius},
+		{"SauvolaSurface with zero range", func() (Surface, error) { return SauvolaSurface(im, 3, 0.2, 0) }, ErrInvalidRange},
+		{"SauvolaSurface with negative range", func() (Surface, error) { return SauvolaSurface(im, 3, 0.2, -1) }, ErrInvalidRange},
+		{"SauvolaSurface with NaN range", func() (Surface, error) { return SauvolaSurface(im, 3, 0.2, math.NaN()) }, ErrInvalidRange},
+	}
+	for _, tt := range tests {
+		s, err := tt.f()
+		if err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+		if err == nil && s.Bounds() != im.Rect {
+			t.Errorf("%s: got bounds %v, want %v", tt.name, s.Bounds(), im.Rect)
+		}
+	}
+}
+
+func TestSurfaceBoundsMismatch(t *testing.T) {
+	im := image.NewGray(image.Rect(0, 0, 8, 6))
+	tests := []struct {
+		name string
+		s    Surface
+		want error
+	}{
+		{"same bounds", ConstantSurface(im.Rect, 128), nil},
+		{"larger", ConstantSurface(image.Rect(0, 0, 9, 6), 128), ErrBoundsMismatch},
+		{"smaller", ConstantSurface(image.Rect(0, 0, 8, 5), 128), ErrBoundsMismatch},
+		{"shifted", ConstantSurface(im.Rect.Add(image.Pt(1, 0)), 128), ErrBoundsMismatch},
+		{"zero Surface", Surface{}, ErrBoundsMismatch},
+	}
+	for _, tt := range tests {
+		if _, err := SurfaceThreshold(im, tt.s); err != tt.want {
+			t.Errorf("%s: SurfaceThreshold got %v, want %v", tt.name, err, tt.want)
+		}
+		if _, err := InverseSurfaceThreshold(im, tt.s); err != tt.want {
+			t.Errorf("%s: InverseSurfaceThreshold got %v, want %v", tt.name, err, tt.want)
+		}
+		if _, err := SurfaceSuperHulls(im, tt.s, 0); err != tt.want {
+			t.Errorf("%s: SurfaceSuperHulls got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSurfaceSuperHullsOffset(t *testing.T) {
+	im, err := GaussianBlur(invert(grayRows(
+		"................",
+		"................",
+		"...####.........",
+		"...####.........",
+		"...####...###...",
+		"..........###...",
+		"..........###...",
+		"................",
+		"................",
+	)), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same pixels with bounds that do not start at the origin
+	shifted := image.NewGray(im.Rect.Add(image.Pt(5, -3)))
+	copy(shifted.Pix, im.Pix)
+
+	for _, r := range []int{1, 2, 4} {
+		s, err := SauvolaSurface(im, r, 0.2, 128)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ss, err := SauvolaSurface(shifted, r, 0.2, 128)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := SurfaceSuperHulls(im, s, 1)
+		if err != nil || len(want) == 0 {
+			t.Errorf("r = %d: no Hulls: %v", r, err)
+		}
+		if got, err := SurfaceSuperHulls(shifted, ss, 1); err != nil || !equalHulls(got, want) {
+			t.Errorf("r = %d: Hulls of the shifted image differ from those at the origin: %v", r, err)
+		}
+	}
+}
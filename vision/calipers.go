@@ -0,0 +1,92 @@
+package vision
+
+import (
+	"math"
+	"screwSort/geometry"
+	"screwSort/utility"
+)
+
+// MinAreaRect returns the minimum-area Rectangle enclosing the Hull found by rotating calipers around its convex hull
+//
+// The Rectangle is oriented so that its width is along one edge of the convex hull
+func (h Hull) MinAreaRect() geometry.Rectangle {
+	r, _, _ := h.calipers()
+	return r
+}
+
+// MinimumWidth returns the smallest distance between two parallel lines enclosing the Hull
+func (h Hull) MinimumWidth() float64 {
+	_, w, _ := h.calipers()
+	return w
+}
+
+// Diameter returns the largest distance between two points of the Hull
+func (h Hull) Diameter() float64 {
+	_, _, d := h.calipers()
+	return d
+}
+
+// calipers rotates calipers around the convex hull of the Hull and returns the minimum-area enclosing Rectangle,
+// the minimum width, and the diameter
+func (h Hull) calipers() (geometry.Rectangle, float64, float64) {
+	// collinear points reduce to the two ends of their segment and duplicates to a single point
+	ps := geometry.ConvexHull(h.ps)
+	n := len(ps)
+	switch n {
+	case 0:
+		return geometry.Rectangle{}, 0, 0
+	case 1:
+		return geometry.RectangleCWHA(ps[0], 0, 0, 0), 0, 0
+	case 2:
+		s := geometry.SegmentPQ(ps[0], ps[1])
+		return geometry.RectangleCWHA(s.Center(), s.Length(), 0, s.D().Theta()), 0, s.Length()
+	}
+
+	at := func(i int) geometry.Point {
+		return ps[i%n]
+	}
+	var r geometry.Rectangle
+	am, wm, dm := math.Inf(1), math.Inf(1), 0.
+	k, j, l := 1, 1, 1
+	for i := 0; i < n; i++ {
+		p := at(i)
+		e := at(i + 1).Subtract(p)
+		if e.R() == 0 {
+			continue
+		}
+		u := e.Scale(1 / e.R())
+		along := func(q geometry.Point) float64 {
+			return u.Dot(q.Subtract(p))
+		}
+		across := func(q geometry.Point) float64 {
+			return math.Abs(u.Cross(q.Subtract(p)))
+		}
+
+		k = utility.Max(k, i+1)
+		for c := 0; c < n && along(at(k+1)) >= along(at(k)); c++ {
+			k++
+		}
+		j = utility.Max(j, k)
+		for c := 0; c < n && across(at(j+1)) >= across(at(j)); c++ {
+			j++
+		}
+		l = utility.Max(l, j)
+		for c := 0; c < n && along(at(l+1)) <= along(at(l)); c++ {
+			l++
+		}
+
+		width, height := along(at(k))-along(at(l)), across(at(j))
+		dm = math.Max(dm, math.Max(p.DistanceTo(at(j)), at(i+1).DistanceTo(at(j))))
+		wm = math.Min(wm, height)
+		if a := width * height; a < am {
+			am = a
+			v := geometry.PointXY(-u.Y(), u.X())
+			if v.Dot(at(j).Subtract(p)) < 0 {
+				v = v.Scale(-1)
+			}
+			c := p.Add(u.Scale((along(at(k)) + along(at(l))) / 2)).Add(v.Scale(height / 2))
+			r = geometry.RectangleCWHA(c, width, height, u.Theta())
+		}
+	}
+	return r, wm, dm
+}
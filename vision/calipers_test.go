@@ -0,0 +1,104 @@
+package vision
+
+import (
+	"math"
+	"screwSort/geometry"
+	"testing"
+)
+
+// calipersBruteForce returns the minimum enclosing Rectangle area, the minimum width, and the diameter of the points
+// by trying the direction through every pair of them, one of which is along an edge of the optimal Rectangle
+func calipersBruteForce(ps []geometry.Point) (float64, float64, float64) {
+	am, wm, dm := math.Inf(1), math.Inf(1), 0.
+	for i, p := range ps {
+		for _, q := range ps[i+1:] {
+			dm = math.Max(dm, p.DistanceTo(q))
+			e := q.Subtract(p)
+			if e.R() == 0 {
+				continue
+			}
+			u := e.Scale(1 / e.R())
+			aMin, aMax, cMin, cMax := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+			for _, r := range ps {
+				a, c := u.Dot(r), u.Cross(r)
+				aMin, aMax, cMin, cMax = math.Min(aMin, a), math.Max(aMax, a), math.Min(cMin, c), math.Max(cMax, c)
+			}
+			am = math.Min(am, (aMax-aMin)*(cMax-cMin))
+			wm = math.Min(wm, cMax-cMin)
+		}
+	}
+	return am, wm, dm
+}
+
+func TestCalipers(t *testing.T) {
+	q := geometry.PointXY(10, 20)
+	rotated := hullXY(4, 16, 16, 16, 16, 24, 4, 24, 10, 18, 12, 21).RotateAbout(q, 0.5)
+	var scattered []float64
+	for i := 0; i < 40; i++ {
+		scattered = append(scattered, float64((i*37)%23)+0.1*float64(i%7), float64((i*53)%17)-0.3*float64(i%5))
+	}
+	tests := []struct {
+		name string
+		h    Hull
+	}{
+		{"rotated rectangle", rotated},
+		{"triangle", hullXY(0, 0, 6, 0, 1, 4)},
+		{"obtuse triangle", hullXY(0, 0, 10, 0, 8, 1)},
+		{"pentagon", polygonDisk(5, 5, 3, 5)},
+		{"scattered points", hullXY(scattered...)},
+	}
+	const eps = 1e-9
+	for _, tt := range tests {
+		area, width, diameter := calipersBruteForce(tt.h.Ps())
+		r := tt.h.MinAreaRect()
+		if math.Abs(r.Area()-area) > eps*area {
+			t.Errorf("%s: MinAreaRect().Area() = %v, want %v", tt.name, r.Area(), area)
+		}
+		if got := tt.h.MinimumWidth(); math.Abs(got-width) > eps*width {
+			t.Errorf("%s: MinimumWidth() = %v, want %v", tt.name, got, width)
+		}
+		if got := tt.h.Diameter(); math.Abs(got-diameter) > eps*diameter {
+			t.Errorf("%s: Diameter() = %v, want %v", tt.name, got, diameter)
+		}
+		// every point lies inside the Rectangle
+		for _, p := range tt.h.Ps() {
+			v := p.Subtract(r.Center()).Rotate(-r.Angle())
+			if math.Abs(v.X()) > r.Width()/2+1e-9 || math.Abs(v.Y()) > r.Height()/2+1e-9 {
+				t.Errorf("%s: point %v lies outside %v", tt.name, p, r)
+			}
+		}
+	}
+
+	// the rectangle is found in its own orientation
+	if r := rotated.MinAreaRect(); r.Center().DistanceTo(q) > eps || math.Abs(r.Length()-12) > eps || math.Abs(r.Breadth()-8) > eps {
+		t.Errorf("rotated rectangle: MinAreaRect() = %v, want 12×8 about %v", r, q)
+	}
+}
+
+func TestCalipersDegenerate(t *testing.T) {
+	tests := []struct {
+		name            string
+		h               Hull
+		length, breadth float64
+		width, diameter float64
+	}{
+		{"empty", HullPs(nil), 0, 0, 0, 0},
+		{"one point", hullXY(3, 4), 0, 0, 0, 0},
+		{"two points", hullXY(0, 0, 3, 4), 5, 0, 0, 5},
+		{"collinear", hullXY(0, 0, 3, 4, 1.5, 2, 6, 8), 10, 0, 0, 10},
+		{"collinear with duplicates", hullXY(0, 0, 0, 0, 6, 8, 3, 4, 6, 8), 10, 0, 0, 10},
+	}
+	const eps = 1e-9
+	for _, tt := range tests {
+		r := tt.h.MinAreaRect()
+		if math.Abs(r.Length()-tt.length) > eps || math.Abs(r.Breadth()-tt.breadth) > eps {
+			t.Errorf("%s: MinAreaRect() = %v, want %v×%v", tt.name, r, tt.length, tt.breadth)
+		}
+		if got := tt.h.MinimumWidth(); math.Abs(got-tt.width) > eps {
+			t.Errorf("%s: MinimumWidth() = %v, want %v", tt.name, got, tt.width)
+		}
+		if got := tt.h.Diameter(); math.Abs(got-tt.diameter) > eps {
+			t.Errorf("%s: Diameter() = %v, want %v", tt.name, got, tt.diameter)
+		}
+	}
+}
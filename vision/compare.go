@@ -0,0 +1,164 @@
+package vision
+
+import (
+	"math"
+	"screwSort/fit"
+	"screwSort/geometry"
+	"screwSort/utility"
+)
+
+// Invariance selects the transformations that hull comparisons should ignore
+//
+// Comparisons are always invariant to translation and to the starting point of the hulls
+type Invariance uint8
+
+const (
+	RotationInvariant Invariance = 1 << iota
+	ScaleInvariant
+)
+
+// Resample returns a new Hull with n points spaced evenly along the boundary of the Hull
+func (h Hull) Resample(n int) Hull {
+	m := len(h.ps)
+	if m == 0 || n <= 0 {
+		return HullPs(nil)
+	}
+	l := h.Perimeter()
+	ps := make([]geometry.Point, 0, n)
+	if l == 0 {
+		for i := 0; i < n; i++ {
+			ps = append(ps, h.ps[0])
+		}
+		return h.derive(ps)
+	}
+
+	step := l / float64(n)
+	t := 0.
+	for i := 0; len(ps) < n; i = (i + 1) % m {
+		p, q := h.ps[i], h.ps[(i+1)%m]
+		d := p.DistanceTo(q)
+		for ; t < d && len(ps) < n; t += step {
+			f := 0.
+			if d > 0 {
+				f = t / d
+			}
+			ps = append(ps, geometry.PointXY(p.X()+f*(q.X()-p.X()), p.Y()+f*(q.Y()-p.Y())))
+		}
+		t -= d
+	}
+	return h.derive(ps)
+}
+
+// FrechetDistance returns the discrete Fréchet distance between the Hulls after resampling both to n points,
+// minimized over the starting point and the transformations selected by the Invariance
+func FrechetDistance(h, o Hull, n int, inv Invariance) float64 {
+	return minimizeAlignment(h, o, n, inv, frechet)
+}
+
+// DTWDistance returns the mean dynamic time warping cost between the Hulls after resampling both to n points
+// with the warping path confined to a band of the given width,
+// minimized over the starting point and the transformations selected by the Invariance
+func DTWDistance(h, o Hull, n, window int, inv Invariance) float64 {
+	return minimizeAlignment(h, o, n, inv, func(ps, qs []geometry.Point) float64 {
+		return dtw(ps, qs, window) / float64(n)
+	})
+}
+
+// minimizeAlignment returns the least distance between the aligned points of the Hulls over every starting point
+// of the second
+//
+// If the comparison is RotationInvariant, the second Hull is rotated for each starting point by the angle
+// that minimizes the sum of squared distances between corresponding points, found in closed form
+func minimizeAlignment(h, o Hull, n int, inv Invariance, dist func(ps, qs []geometry.Point) float64) float64 {
+	if len(h.ps) == 0 || len(o.ps) == 0 || n <= 0 {
+		return math.Inf(1)
+	}
+	ps, qs := alignedPs(h, n, inv), alignedPs(o, n, inv)
+
+	dm := math.Inf(1)
+	rs := make([]geometry.Point, n)
+	for k := 0; k < n; k++ {
+		a := 0.
+		if inv&RotationInvariant != 0 {
+			a = procrustesAngle(ps, qs, k)
+		}
+		for i := range rs {
+			rs[i] = qs[(i+k)%n].Rotate(a)
+		}
+		dm = math.Min(dm, dist(ps, rs))
+	}
+	return dm
+}
+
+// procrustesAngle returns the angle by which rotating the centered points qs, starting from index k,
+// minimizes the sum of their squared distances to the corresponding centered points ps
+func procrustesAngle(ps, qs []geometry.Point, k int) float64 {
+	n := len(ps)
+	var dot, cross float64
+	for i, p := range ps {
+		q := qs[(i+k)%n]
+		dot += p.X()*q.X() + p.Y()*q.Y()
+		cross += p.Y()*q.X() - p.X()*q.Y()
+	}
+	return math.Atan2(cross, dot)
+}
+
+// alignedPs resamples the Hull, orients it counterclockwise, centers it at the origin,
+// and normalizes its scale according to the Invariance
+func alignedPs(h Hull, n int, inv Invariance) []geometry.Point {
+	ps := h.Resample(n).Orient(false).ps
+
+	xBar, yBar, _, x2Bar, y2Bar := fit.Moments(ps)
+	f := 1.
+	if inv&ScaleInvariant != 0 {
+		if rms := math.Sqrt(x2Bar - xBar*xBar + y2Bar - yBar*yBar); rms > 0 {
+			f = 1 / rms
+		}
+	}
+	for i, p := range ps {
+		ps[i] = p.Translate(-xBar, -yBar).Scale(f)
+	}
+	return ps
+}
+
+func frechet(ps, qs []geometry.Point) float64 {
+	n, m := len(ps), len(qs)
+	prev, cur := make([]float64, m), make([]float64, m)
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			d := ps[i].DistanceTo(qs[j])
+			switch {
+			case i == 0 && j == 0:
+				cur[j] = d
+			case i == 0:
+				cur[j] = math.Max(cur[j-1], d)
+			case j == 0:
+				cur[j] = math.Max(prev[j], d)
+			default:
+				cur[j] = math.Max(utility.Min(prev[j], prev[j-1], cur[j-1]), d)
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[m-1]
+}
+
+func dtw(ps, qs []geometry.Point, window int) float64 {
+	n, m := len(ps), len(qs)
+	window = utility.Max(window, utility.AbsInt(n-m))
+	prev, cur := make([]float64, m+1), make([]float64, m+1)
+	for j := range prev {
+		prev[j] = math.Inf(1)
+	}
+	prev[0] = 0
+	for i := 1; i <= n; i++ {
+		for j := range cur {
+			cur[j] = math.Inf(1)
+		}
+		for j := utility.Max(1, i-window); j <= utility.Min(m, i+window); j++ {
+			cur[j] = ps[i-1].DistanceTo(qs[j-1]) + utility.Min(prev[j], cur[j-1], prev[j-1])
+		}
+		prev, cur = cur, prev
+	}
+	return prev[m]
+}
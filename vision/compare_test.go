@@ -0,0 +1,88 @@
+package vision
+
+import (
+	"math"
+	"screwSort/geometry"
+	"testing"
+)
+
+// transformedHull returns the Hull through the points after scaling by f, rotating by a about the origin,
+// translating by (dx, dy), and starting from the point at index k
+func transformedHull(ps []geometry.Point, f, a, dx, dy float64, k int) Hull {
+	qs := make([]geometry.Point, len(ps))
+	for i := range qs {
+		qs[i] = ps[(i+k)%len(ps)].Scale(f).Rotate(a).Translate(dx, dy)
+	}
+	return HullPs(qs)
+}
+
+func TestCompareInvariance(t *testing.T) {
+	shapes := map[string][]geometry.Point{
+		// the second moments of an equilateral triangle are the same along every axis
+		"triangle": {geometry.PointXY(0, 0), geometry.PointXY(10, 0), geometry.PointXY(5, 5*math.Sqrt(3))},
+		"L": {
+			geometry.PointXY(0, 0), geometry.PointXY(4, 0), geometry.PointXY(4, 8), geometry.PointXY(10, 8),
+			geometry.PointXY(10, 12), geometry.PointXY(0, 12),
+		},
+	}
+	const n = 64
+	tests := []struct {
+		name string
+		f, a float64
+		inv  Invariance
+	}{
+		{"translated", 1, 0, 0},
+		{"rotated by 20°", 1, 20 * math.Pi / 180, RotationInvariant},
+		{"rotated by 73°", 1, 73 * math.Pi / 180, RotationInvariant},
+		{"rotated by 200°", 1, 200 * math.Pi / 180, RotationInvariant},
+		{"scaled", 2.5, 0, ScaleInvariant},
+		{"scaled and rotated", 0.4, -2, RotationInvariant | ScaleInvariant},
+	}
+	for name, ps := range shapes {
+		h := HullPs(ps)
+		for _, tt := range tests {
+			o := transformedHull(ps, tt.f, tt.a, 30, -7, 1)
+			// the resampled points can be off by up to half a step
+			tol := h.Perimeter() / n
+			if tt.inv&ScaleInvariant != 0 {
+				tol = 0.1
+			}
+			if d := FrechetDistance(h, o, n, tt.inv); d > tol {
+				t.Errorf("%s %s: FrechetDistance = %v, want at most %v", name, tt.name, d, tol)
+			}
+			if d := DTWDistance(h, o, n, 4, tt.inv); d > tol {
+				t.Errorf("%s %s: DTWDistance = %v, want at most %v", name, tt.name, d, tol)
+			}
+		}
+	}
+}
+
+func TestCompareNearlyIsotropic(t *testing.T) {
+	// the principal axis of a square is undefined, so it cannot be used to align the square with a clipped copy
+	square := []geometry.Point{
+		geometry.PointXY(0, 0), geometry.PointXY(10, 0), geometry.PointXY(10, 10), geometry.PointXY(0, 10),
+	}
+	clipped := []geometry.Point{
+		geometry.PointXY(0, 0), geometry.PointXY(10, 0), geometry.PointXY(10, 9), geometry.PointXY(9, 10),
+		geometry.PointXY(0, 10),
+	}
+	for _, a := range []float64{0.1, 0.5, 1, 2, 3} {
+		if d := FrechetDistance(HullPs(square), transformedHull(clipped, 1, a, 3, 4, 2), 64, RotationInvariant); d > 1 {
+			t.Errorf("rotated by %v: FrechetDistance = %v, want at most 1", a, d)
+		}
+	}
+}
+
+func TestCompareDistinguishes(t *testing.T) {
+	square := HullPs([]geometry.Point{
+		geometry.PointXY(0, 0), geometry.PointXY(10, 0), geometry.PointXY(10, 10), geometry.PointXY(0, 10),
+	})
+	rectangle := HullPs([]geometry.Point{
+		geometry.PointXY(0, 0), geometry.PointXY(20, 0), geometry.PointXY(20, 5), geometry.PointXY(0, 5),
+	})
+	for _, inv := range []Invariance{0, RotationInvariant, RotationInvariant | ScaleInvariant} {
+		if d := FrechetDistance(square, rectangle, 64, inv); d < 1 && inv&ScaleInvariant == 0 || d < 0.1 {
+			t.Errorf("invariance %d: FrechetDistance between a square and a rectangle = %v", inv, d)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package vision
+
+import (
+	"screwSort/geometry"
+	"sort"
+	"sync"
+)
+
+// contourGrid describes the cells of marching squares over an image surrounded by a virtual border of one pixel
+// that is always outside, so that every contour closes
+//
+// Cell (x, y) has the centers of the padded pixels (x, y), (x+1, y), (x+1, y+1), and (x, y+1) as its corners,
+// clockwise from the top left, where padded pixel (x, y) is pixel (x-1, y-1) of the image.
+// The sides of a cell are numbered clockwise from 0 at the top, so that side s runs from corner s to corner s+1.
+// A contour enters a cell through a side whose corners go from outside to inside and leaves through one
+// whose corners go from inside to outside, which keeps the inside on its left and winds outer contours
+// counterclockwise
+//
+// A binary grid places every point midway between the crossings of its sides,
+// since the values of a binary image have no gradient for a Newton step to follow
+//
+// The Workers of a grid split the rows both when classifying its corners and when tracing its contours
+type contourGrid struct {
+	dx, dy       int
+	cx, cy       int
+	inside       []bool
+	value, level func(x, y int) float64
+	binary       bool
+	ws           Workers
+}
+
+// contourArc identifies the passage of a contour through a cell by the cell and the side it enters through
+type contourArc int
+
+// contourFragment describes a run of arcs traced within one strip of cell rows
+type contourFragment struct {
+	start, next contourArc
+	ps          []geometry.Point
+	min         contourArc
+	iMin        int
+	truncated   bool
+}
+
+// contour describes a closed contour starting at its first arc in raster order
+type contour struct {
+	start     contourArc
+	ps        []geometry.Point
+	truncated bool
+}
+
+var (
+	cornerOffsets = [4][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	sideOffsets   = [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+)
+
+// closedArc marks the end of a fragment that closes on itself
+const closedArc contourArc = -1
+
+func newContourGrid(dx, dy int, value, level func(x, y int) float64, ws Workers) contourGrid {
+	g := contourGrid{dx, dy, dx + 1, dy + 1, make([]bool, (dx+2)*(dy+2)), value, level, false, ws}
+	ws.parallelRows(dy, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < dx; x++ {
+				g.inside[(y+1)*(dx+2)+x+1] = value(x, y) <= level(x, y)
+			}
+		}
+	})
+	return g
+}
+
+func (g contourGrid) arc(x, y, side int) contourArc {
+	return contourArc(4*(y*g.cx+x) + side)
+}
+
+func (g contourGrid) cell(a contourArc) (int, int, int) {
+	c := int(a) / 4
+	return c % g.cx, c / g.cx, int(a) % 4
+}
+
+func (g contourGrid) corners(x, y int) [4]bool {
+	var in [4]bool
+	for k, o := range cornerOffsets {
+		in[k] = g.inside[(y+o[1])*(g.dx+2)+x+o[0]]
+	}
+	return in
+}
+
+// border returns whether a corner of the cell lies on the virtual border
+func (g contourGrid) border(x, y int) bool {
+	return x == 0 || y == 0 || x == g.cx-1 || y == g.cy-1
+}
+
+// entries returns whether the contour enters the cell through each side
+func (g contourGrid) entries(x, y int) [4]bool {
+	in := g.corners(x, y)
+	var es [4]bool
+	for s := range es {
+		es[s] = !in[s] && in[(s+1)%4]
+	}
+	return es
+}
+
+// exit returns the side through which the contour that entered the cell through the side leaves it,
+// joining the inside corners of a saddle if the mean of the cell is inside
+func (g contourGrid) exit(x, y, side int) int {
+	in := g.corners(x, y)
+	if in[0] == in[2] && in[1] == in[3] && in[0] != in[1] {
+		if g.centerInside(x, y) {
+			return (side + 3) % 4
+		}
+		return (side + 1) % 4
+	}
+	for s := range in {
+		if in[s] && !in[(s+1)%4] {
+			return s
+		}
+	}
+	return side
+}
+
+// centerInside returns whether the mean of the values of the cell is within the mean of its levels,
+// which is only needed at saddles and so never on the virtual border
+func (g contourGrid) centerInside(x, y int) bool {
+	var v, t float64
+	for _, o := range cornerOffsets {
+		v += g.value(x+o[0]-1, y+o[1]-1)
+		t += g.level(x+o[0]-1, y+o[1]-1)
+	}
+	return v <= t
+}
+
+// point returns the sub-pixel location of the contour passing through the cell from the side to the exit
+//
+// The contour crosses a cell with one Newton step along the gradient from its center,
+// or through the middle of the crossings of its sides at a saddle, which it passes twice,
+// on the virtual border, and everywhere in a binary grid
+func (g contourGrid) point(x, y, side, exit int) geometry.Point {
+	in := g.corners(x, y)
+	if in[0] == in[2] && in[1] == in[3] || g.border(x, y) || g.binary {
+		return g.crossing(x, y, side).AverageWith(g.crossing(x, y, exit))
+	}
+	vTL, vTR, vBL, vBR := g.value(x-1, y-1), g.value(x, y-1), g.value(x-1, y), g.value(x, y)
+	tTL, tTR, tBL, tBR := g.level(x-1, y-1), g.level(x, y-1), g.level(x-1, y), g.level(x, y)
+	gx, gy := (vTR-vBL+vBR-vTL)/2, (-vTR+vBL+vBR-vTL)/2
+	dv := (tTL+tTR+tBL+tBR)/4 - (vTL+vTR+vBL+vBR)/4
+	s := dv / (gx*gx + gy*gy)
+	return geometry.PointXY(float64(x)+s*gx, float64(y)+s*gy)
+}
+
+// crossing returns the point on the side of the cell where the values cross the levels by linear interpolation,
+// or the edge of the image if one of its corners is on the virtual border
+func (g contourGrid) crossing(x, y, side int) geometry.Point {
+	oa, ob := cornerOffsets[side], cornerOffsets[(side+1)%4]
+	xa, ya, xb, yb := x+oa[0]-1, y+oa[1]-1, x+ob[0]-1, y+ob[1]-1
+	t := 0.5
+	if xa >= 0 && ya >= 0 && xa < g.dx && ya < g.dy && xb >= 0 && yb >= 0 && xb < g.dx && yb < g.dy {
+		va, vb, ta, tb := g.value(xa, ya), g.value(xb, yb), g.level(xa, ya), g.level(xb, yb)
+		if d := (vb - va) - (tb - ta); d != 0 {
+			t = clampUnit((ta - va) / d)
+		}
+	}
+	pa := geometry.PointImage(xa, ya)
+	return geometry.PointXY(pa.X()+t*float64(xb-xa), pa.Y()+t*float64(yb-ya))
+}
+
+func clampUnit(t float64) float64 {
+	if t < 0 {
+		return 0
+	}
+	if t > 1 {
+		return 1
+	}
+	return t
+}
+
+// trace follows the contour from the arc until it closes or leaves the cell rows {y0, ..., y1-1}
+func (g contourGrid) trace(a contourArc, y0, y1 int, visited []bool) contourFragment {
+	f := contourFragment{start: a, next: closedArc, min: a}
+	for {
+		visited[a] = true
+		if a < f.min {
+			f.min, f.iMin = a, len(f.ps)
+		}
+		x, y, side := g.cell(a)
+		exit := g.exit(x, y, side)
+		f.ps = append(f.ps, g.point(x, y, side, exit))
+		f.truncated = f.truncated || g.border(x, y)
+
+		nx, ny := x+sideOffsets[exit][0], y+sideOffsets[exit][1]
+		a = g.arc(nx, ny, (exit+2)%4)
+		if ny < y0 || ny >= y1 {
+			f.next = a
+			return f
+		}
+		if a == f.start {
+			return f
+		}
+	}
+}
+
+// traceStrip traces every arc of the cell rows {y0, ..., y1-1}, first from the arcs entering from outside the strip
+// so that every fragment starts where its contour enters, and then around the contours closed within the strip
+func (g contourGrid) traceStrip(y0, y1 int, visited []bool) []contourFragment {
+	var fs []contourFragment
+	for _, y := range []int{y0, y1 - 1} {
+		for x := 0; x < g.cx; x++ {
+			for side, e := range g.entries(x, y) {
+				ny := y + sideOffsets[side][1]
+				if e && (ny < y0 || ny >= y1) && !visited[g.arc(x, y, side)] {
+					fs = append(fs, g.trace(g.arc(x, y, side), y0, y1, visited))
+				}
+			}
+		}
+	}
+	for y := y0; y < y1; y++ {
+		for x := 0; x < g.cx; x++ {
+			for side, e := range g.entries(x, y) {
+				if e && !visited[g.arc(x, y, side)] {
+					fs = append(fs, g.trace(g.arc(x, y, side), y0, y1, visited))
+				}
+			}
+		}
+	}
+	return fs
+}
+
+// contours traces the iso-contours of the grid in strips of cell rows in parallel, stitches the fragments
+// that cross between strips, and returns the contours in raster order of their first arc
+//
+// Every contour starts at its first arc in raster order so that the result does not depend on how the rows are split
+func (g contourGrid) contours() []contour {
+	visited := make([]bool, 4*g.cx*g.cy)
+	var fs []contourFragment
+	var mu sync.Mutex
+	g.ws.parallelRows(g.cy, func(y0, y1 int) {
+		strip := g.traceStrip(y0, y1, visited)
+		mu.Lock()
+		fs = append(fs, strip...)
+		mu.Unlock()
+	})
+	sort.Slice(fs, func(i, j int) bool { return fs[i].start < fs[j].start })
+	byStart := make(map[contourArc]int, len(fs))
+	for i, f := range fs {
+		byStart[f.start] = i
+	}
+
+	var cs []contour
+	used := make([]bool, len(fs))
+	for i := range fs {
+		if used[i] {
+			continue
+		}
+		c := contour{start: fs[i].min}
+		iMin := fs[i].iMin
+		for j := i; !used[j]; {
+			used[j] = true
+			f := fs[j]
+			if f.min < c.start {
+				c.start, iMin = f.min, len(c.ps)+f.iMin
+			}
+			c.ps = append(c.ps, f.ps...)
+			c.truncated = c.truncated || f.truncated
+			if f.next == closedArc {
+				break
+			}
+			j = byStart[f.next]
+		}
+		c.ps = append(c.ps[iMin:], c.ps[:iMin]...)
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].start < cs[j].start })
+	return cs
+}
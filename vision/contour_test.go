@@ -0,0 +1,62 @@
+package vision
+
+import (
+	"image"
+	"testing"
+)
+
+func TestContoursDeterministic(t *testing.T) {
+	disks, err := GaussianBlur(invert(diskImage(90, 70, [3]float64{20, 20, 12}, [3]float64{45, 35, 15}, [3]float64{70, 50, 10})), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		im    *image.Gray
+		level float64
+	}{
+		{"disks", disks, 127.5},
+		{"test pattern", parallelImage(), 100.5},
+		{"test pattern at a high level", parallelImage(), 200.5},
+	}
+	for _, tt := range tests {
+		value, level := grayValue(tt.im), func(int, int) float64 { return tt.level }
+		want := newContourGrid(tt.im.Rect.Dx(), tt.im.Rect.Dy(), value, level, 1).contours()
+		if len(want) == 0 {
+			t.Errorf("%s: no contours", tt.name)
+			continue
+		}
+		for i := 1; i < len(want); i++ {
+			if want[i].start <= want[i-1].start {
+				t.Errorf("%s: contour %d starts at arc %d after arc %d", tt.name, i, want[i].start, want[i-1].start)
+			}
+		}
+		// repeat with every worker count so that a race between strips would show up as a difference
+		for _, ws := range []Workers{1, 2, 3, 5, 8} {
+			for run := 0; run < 3; run++ {
+				got := newContourGrid(tt.im.Rect.Dx(), tt.im.Rect.Dy(), value, level, ws).contours()
+				if !equalContours(got, want) {
+					t.Errorf("%s: run %d with %d workers differs from the sequential contours", tt.name, run, ws)
+				}
+			}
+		}
+	}
+}
+
+// equalContours reports whether the contours start at the same arcs and have exactly the same points
+func equalContours(cs, ds []contour) bool {
+	if len(cs) != len(ds) {
+		return false
+	}
+	for i := range cs {
+		if cs[i].start != ds[i].start || cs[i].truncated != ds[i].truncated || len(cs[i].ps) != len(ds[i].ps) {
+			return false
+		}
+		for j, p := range cs[i].ps {
+			if p != ds[i].ps[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package vision
+
+import (
+	"screwSort/geometry"
+	"sort"
+)
+
+// ConvexityDefect describes a pocket where the Hull departs from its convex hull between two vertices of it,
+// such as the pit between two crests of a thread
+type ConvexityDefect struct {
+	start, end, deepest int
+	depth               float64
+}
+
+// Start returns the index of the point of the Hull where the ConvexityDefect leaves the convex hull
+func (d ConvexityDefect) Start() int {
+	return d.start
+}
+
+// End returns the index of the point of the Hull where the ConvexityDefect returns to the convex hull
+func (d ConvexityDefect) End() int {
+	return d.end
+}
+
+// Deepest returns the index of the point of the Hull farthest from the convex hull within the ConvexityDefect
+func (d ConvexityDefect) Deepest() int {
+	return d.deepest
+}
+
+// Depth returns the distance of the deepest point from the edge of the convex hull spanning the ConvexityDefect
+func (d ConvexityDefect) Depth() float64 {
+	return d.depth
+}
+
+// ConvexityDefects returns the ConvexityDefects of the Hull at least depthMin deep in order along the Hull
+func (h Hull) ConvexityDefects(depthMin float64) ([]ConvexityDefect, error) {
+	n := len(h.ps)
+	if n == 0 {
+		return nil, ErrEmptyHull
+	}
+	is := geometry.ConvexHullIndices(h.ps)
+	if len(is) < 3 {
+		return nil, ErrDegenerateHull
+	}
+	// the vertices of the convex hull of a simple polygon appear along it in the same cyclic order
+	sort.Ints(is)
+
+	var ds []ConvexityDefect
+	for k, i := range is {
+		j := is[(k+1)%len(is)]
+		p, q := h.ps[i], h.ps[j]
+		s := geometry.SegmentPQ(p, q)
+		d := ConvexityDefect{i, j, -1, 0}
+		// points exactly on the edge, such as the crests of a thread, split it into separate ConvexityDefects
+		for m := (i + 1) % n; m != (j+1)%n; m = (m + 1) % n {
+			if m == j || p.OrientationSign(q, h.ps[m]) == 0 {
+				if d.end = m; d.deepest >= 0 && d.depth >= depthMin {
+					ds = append(ds, d)
+				}
+				d = ConvexityDefect{m, j, -1, 0}
+			} else if e := s.PerpDistanceTo(h.ps[m]); d.deepest < 0 || e > d.depth {
+				d.deepest, d.depth = m, e
+			}
+		}
+	}
+	return ds, nil
+}
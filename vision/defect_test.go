@@ -0,0 +1,65 @@
+package vision
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvexityDefects(t *testing.T) {
+	// three pits 3 deep between crests on the top edge of the convex hull and a notch 2 deep in the bottom
+	toothed := hullXY(0, 0, 2, 3, 4, 0, 6, 3, 8, 0, 10, 3, 12, 0, 12, 10, 6, 8, 0, 10)
+	type defect struct {
+		start, end, deepest int
+		depth               float64
+	}
+	tests := []struct {
+		name     string
+		h        Hull
+		depthMin float64
+		want     []defect
+	}{
+		{"toothed", toothed, 1, []defect{{0, 2, 1, 3}, {2, 4, 3, 3}, {4, 6, 5, 3}, {7, 9, 8, 2}}},
+		{"toothed deeper than the notch", toothed, 2.5, []defect{{0, 2, 1, 3}, {2, 4, 3, 3}, {4, 6, 5, 3}}},
+		{"toothed deeper than the pits", toothed, 4, nil},
+		{"reversed toothed", toothed.Reverse(), 1, []defect{{1, 3, 2, 2}, {4, 6, 5, 3}, {6, 8, 7, 3}, {8, 0, 9, 3}}},
+		{"convex", hullXY(0, 0, 4, 0, 4, 4, 0, 4), 0, nil},
+	}
+	for _, tt := range tests {
+		ds, err := tt.h.ConvexityDefects(tt.depthMin)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(ds) != len(tt.want) {
+			t.Errorf("%s: got %d ConvexityDefects, want %d", tt.name, len(ds), len(tt.want))
+			continue
+		}
+		for i, d := range ds {
+			w := tt.want[i]
+			if d.Start() != w.start || d.End() != w.end || d.Deepest() != w.deepest || math.Abs(d.Depth()-w.depth) > 1e-12 {
+				t.Errorf("%s: ConvexityDefect %d from %d to %d deepest at %d is %v deep, want %v",
+					tt.name, i, d.Start(), d.End(), d.Deepest(), d.Depth(), w)
+			}
+		}
+	}
+
+	// starting inside a pit leaves the same pits
+	ds, err := rotateStart(toothed, 5).ConvexityDefects(1)
+	if err != nil || len(ds) != 4 {
+		t.Fatalf("toothed starting inside a pit: got %d ConvexityDefects and %v, want 4", len(ds), err)
+	}
+	depths := 0.
+	for _, d := range ds {
+		depths += d.Depth()
+	}
+	if math.Abs(depths-11) > 1e-12 {
+		t.Errorf("toothed starting inside a pit: total depth %v, want 11", depths)
+	}
+
+	if _, err := HullPs(nil).ConvexityDefects(0); err != ErrEmptyHull {
+		t.Errorf("empty: got %v, want ErrEmptyHull", err)
+	}
+	if _, err := hullXY(0, 0, 1, 1, 2, 2).ConvexityDefects(0); err != ErrDegenerateHull {
+		t.Errorf("collinear: got %v, want ErrDegenerateHull", err)
+	}
+}
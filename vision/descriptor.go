@@ -0,0 +1,245 @@
+package vision
+
+import (
+	"math"
+	"math/cmplx"
+	"screwSort/geometry"
+	"sort"
+)
+
+// zernikeRadius is the radius in samples of the disc that Zernike maps the Hull into,
+// so that the sampling does not depend on the units of the Hull
+const zernikeRadius = 64
+
+// EllipticFourier returns the first n elliptic Fourier harmonics {a, b, c, d} of the Hull traced counterclockwise,
+// so that harmonic k contributes (a cos kt + b sin kt, c cos kt + d sin kt) to the outline at t ∈ [0, 2π)
+//
+// It returns ErrInvalidHarmonics if n is negative
+func (h Hull) EllipticFourier(n int) ([][4]float64, error) {
+	if n < 0 {
+		return nil, ErrInvalidHarmonics
+	}
+	ps := h.Orient(false).ps
+	m := len(ps)
+	l := h.Perimeter()
+	es := make([][4]float64, n)
+	if m == 0 || l == 0 {
+		return es, nil
+	}
+	for k := 1; k <= n; k++ {
+		w := 2 * float64(k) * math.Pi / l
+		t := 0.
+		var e [4]float64
+		for i, p := range ps {
+			q := ps[(i+1)%m]
+			dx, dy := q.X()-p.X(), q.Y()-p.Y()
+			dt := math.Hypot(dx, dy)
+			if dt == 0 {
+				continue
+			}
+			dc, ds := math.Cos(w*(t+dt))-math.Cos(w*t), math.Sin(w*(t+dt))-math.Sin(w*t)
+			e[0] += dx / dt * dc
+			e[1] += dx / dt * ds
+			e[2] += dy / dt * dc
+			e[3] += dy / dt * ds
+			t += dt
+		}
+		f := l / (2 * float64(k*k) * math.Pi * math.Pi)
+		es[k-1] = [4]float64{e[0] * f, e[1] * f, e[2] * f, e[3] * f}
+	}
+	return es, nil
+}
+
+// NormalizedEllipticFourier returns the first n EllipticFourier harmonics of the Hull normalized by Kuhl and Giardina
+// so that they do not depend on its starting point, rotation, and scale, leaving the first harmonic as {1, 0, 0, d}
+// with |d| the ratio of the axes of its ellipse and the largest coefficient of the even harmonics positive
+//
+// It returns ErrInvalidHarmonics if n is negative
+func (h Hull) NormalizedEllipticFourier(n int) ([][4]float64, error) {
+	es, err := h.EllipticFourier(n)
+	if err != nil || n == 0 {
+		return es, err
+	}
+	a1, b1, c1, d1 := es[0][0], es[0][1], es[0][2], es[0][3]
+	theta := math.Atan2(2*(a1*b1+c1*d1), a1*a1+c1*c1-b1*b1-d1*d1) / 2
+	// the start point moves to the end of the major axis of the first ellipse
+	for k := range es {
+		cs, sn := math.Cos(float64(k+1)*theta), math.Sin(float64(k+1)*theta)
+		a, b, c, d := es[k][0], es[k][1], es[k][2], es[k][3]
+		es[k] = [4]float64{a*cs + b*sn, -a*sn + b*cs, c*cs + d*sn, -c*sn + d*cs}
+	}
+	psi := math.Atan2(es[0][2], es[0][0])
+	e := math.Hypot(es[0][0], es[0][2])
+	if e == 0 {
+		return es, nil
+	}
+	// the major axis of the first ellipse is rotated onto +x and scaled to unit length
+	cs, sn := math.Cos(psi)/e, math.Sin(psi)/e
+	for k := range es {
+		a, b, c, d := es[k][0], es[k][1], es[k][2], es[k][3]
+		es[k] = [4]float64{cs*a + sn*c, cs*b + sn*d, -sn*a + cs*c, -sn*b + cs*d}
+	}
+	// either end of the major axis could be the start point, which negates the even harmonics,
+	// so the end is chosen that makes their coefficient of largest magnitude positive
+	vMax := 0.
+	for k := 1; k < n; k += 2 {
+		for _, v := range es[k] {
+			if math.Abs(v) > math.Abs(vMax) {
+				vMax = v
+			}
+		}
+	}
+	if vMax < 0 {
+		for k := 1; k < n; k += 2 {
+			for i := range es[k] {
+				es[k][i] = -es[k][i]
+			}
+		}
+	}
+	return es, nil
+}
+
+// Zernike returns the magnitudes |A_nm| of the Zernike moments of the region enclosed by the Hull for n ∈ {0, ..., order}
+// and m ∈ {n mod 2, n mod 2 + 2, ..., n}, with the region mapped into the unit disc about its centroid that reaches
+// its farthest point and sampled on a grid of zernikeRadius samples per radius
+//
+// The magnitudes do not depend on the translation, rotation, scale, and units of the Hull apart from sampling.
+// It returns ErrInvalidOrder if the order is negative
+func (h Hull) Zernike(order int) ([]float64, error) {
+	if order < 0 {
+		return nil, ErrInvalidOrder
+	}
+	var as []float64
+	for n := 0; n <= order; n++ {
+		for m := n % 2; m <= n; m += 2 {
+			as = append(as, 0)
+		}
+	}
+	c := h.Centroid()
+	r := 0.
+	for _, p := range h.ps {
+		r = math.Max(r, c.DistanceTo(p))
+	}
+	if r == 0 {
+		return as, nil
+	}
+
+	// the region is scaled about its centroid so that the grid of pixel centers samples it at the same density
+	// whatever the units of the Hull
+	f := zernikeRadius / r
+	unit := h.Map(func(p geometry.Point) geometry.Point { return p.Subtract(c).Scale(f) })
+	zs := make([]complex128, len(as))
+	unit.scanFill(func(x, y float64) {
+		rho, theta := math.Hypot(x, y)/zernikeRadius, math.Atan2(y, x)
+		i := 0
+		for n := 0; n <= order; n++ {
+			for m := n % 2; m <= n; m += 2 {
+				zs[i] += complex(zernikeRadial(n, m, rho), 0) * cmplx.Rect(1, -float64(m)*theta)
+				i++
+			}
+		}
+	})
+	i := 0
+	for n := 0; n <= order; n++ {
+		for m := n % 2; m <= n; m += 2 {
+			as[i] = cmplx.Abs(zs[i]) * float64(n+1) / (math.Pi * zernikeRadius * zernikeRadius)
+			i++
+		}
+	}
+	return as, nil
+}
+
+// zernikeRadial returns the Zernike radial polynomial R_nm at rho
+func zernikeRadial(n, m int, rho float64) float64 {
+	s := 0.
+	for k := 0; k <= (n-m)/2; k++ {
+		c := factorial(n-k) / (factorial(k) * factorial((n+m)/2-k) * factorial((n-m)/2-k))
+		if k%2 == 1 {
+			c = -c
+		}
+		s += c * math.Pow(rho, float64(n-2*k))
+	}
+	return s
+}
+
+func factorial(n int) float64 {
+	f := 1.
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// scanFill calls the function with the center of every pixel inside the Hull using the even-odd rule,
+// one row at a time
+func (h Hull) scanFill(f func(x, y float64)) {
+	if len(h.ps) == 0 {
+		return
+	}
+	pMin, pMax := h.Bounds()
+	n := len(h.ps)
+	var xs []float64
+	for y := math.Floor(pMin.Y()); y < pMax.Y(); y++ {
+		yc := y + 0.5
+		xs = xs[:0]
+		for i, a := range h.ps {
+			c := h.ps[(i+1)%n]
+			if (a.Y() > yc) != (c.Y() > yc) {
+				xs = append(xs, a.X()+(yc-a.Y())*(c.X()-a.X())/(c.Y()-a.Y()))
+			}
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			for x := math.Ceil(xs[i]-0.5) + 0.5; x < xs[i+1]; x++ {
+				f(x, yc)
+			}
+		}
+	}
+}
+
+// Features returns the shape descriptors of the Hull as one vector for classification:
+// the seven Hu invariants scaled as -sign(φ) log₁₀|φ|, the eccentricity, solidity, and circularity,
+// the Zernike magnitudes up to the order, and the NormalizedEllipticFourier coefficients of the harmonics
+//
+// It returns ErrInvalidOrder or ErrInvalidHarmonics if the order or the number of harmonics is negative
+func (h Hull) Features(order, harmonics int) ([]float64, error) {
+	if order < 0 {
+		return nil, ErrInvalidOrder
+	}
+	if harmonics < 0 {
+		return nil, ErrInvalidHarmonics
+	}
+	if len(h.ps) == 0 {
+		return nil, ErrEmptyHull
+	}
+	if h.Area() == 0 {
+		return nil, ErrDegenerateHull
+	}
+	solidity, err := h.Solidity()
+	if err != nil {
+		return nil, err
+	}
+	m := h.Moments()
+	var fs []float64
+	for _, phi := range m.Hu() {
+		v := 0.
+		if phi != 0 {
+			v = -math.Copysign(math.Log10(math.Abs(phi)), phi)
+		}
+		fs = append(fs, v)
+	}
+	fs = append(fs, m.Eccentricity(), solidity, h.Circularity())
+	zs, err := h.Zernike(order)
+	if err != nil {
+		return nil, err
+	}
+	fs = append(fs, zs...)
+	es, err := h.NormalizedEllipticFourier(harmonics)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range es {
+		fs = append(fs, e[:]...)
+	}
+	return fs, nil
+}
@@ -0,0 +1,187 @@
+package vision
+
+import (
+	"fmt"
+	"math"
+	"screwSort/geometry"
+	"testing"
+)
+
+// rotateStart returns the Hull through the same points starting from point i
+func rotateStart(h Hull, i int) Hull {
+	ps := h.Ps()
+	return HullPs(append(append([]geometry.Point{}, ps[i:]...), ps[:i]...))
+}
+
+func TestEllipticFourierCircle(t *testing.T) {
+	// a circle traced counterclockwise at constant speed from its rightmost point has only a first harmonic
+	const r = 6.
+	es, err := polygonDisk(10, 20, r, 2000).EllipticFourier(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// y points down, so counterclockwise on screen is (cos t, -sin t)
+	want := [3][4]float64{{r, 0, 0, -r}}
+	for k := range es {
+		for i := range es[k] {
+			if math.Abs(es[k][i]-want[k][i]) > 1e-3 {
+				t.Errorf("harmonic %d: got %v, want %v", k+1, es[k], want[k])
+				break
+			}
+		}
+	}
+	ns, err := polygonDisk(10, 20, r, 2000).NormalizedEllipticFourier(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first harmonic is normalized to {1, 0, 0, d} with |d| = 1 for equal axes
+	if math.Abs(ns[0][0]-1) > 1e-9 || math.Abs(ns[0][1]) > 1e-3 || math.Abs(ns[0][2]) > 1e-3 || math.Abs(math.Abs(ns[0][3])-1) > 1e-3 {
+		t.Errorf("NormalizedEllipticFourier(1) = %v, want [1 0 0 ±1]", ns[0])
+	}
+}
+
+func TestNormalizedEllipticFourierInvariance(t *testing.T) {
+	h := hullXY(0, 0, 7, 1, 9, 5, 4, 4, 2, 8, -1, 3)
+	const n = 6
+	want, err := h.NormalizedEllipticFourier(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		h    Hull
+	}{
+		{"translated", h.Map(func(p geometry.Point) geometry.Point { return p.Translate(12, -7) })},
+		{"rotated", h.Map(func(p geometry.Point) geometry.Point { return p.Rotate(0.7) })},
+		{"scaled", h.Map(func(p geometry.Point) geometry.Point { return p.Scale(0.05) })},
+		{"reversed", h.Reverse()},
+		{"rotated half a turn", h.Map(func(p geometry.Point) geometry.Point { return p.Rotate(math.Pi) })},
+	}
+	for i := 1; i < len(h.Ps()); i++ {
+		tests = append(tests, struct {
+			name string
+			h    Hull
+		}{fmt.Sprintf("starting at point %d", i), rotateStart(h, i)})
+	}
+	for _, tt := range tests {
+		got, err := tt.h.NormalizedEllipticFourier(n)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for k := range got {
+			for i := range got[k] {
+				if math.Abs(got[k][i]-want[k][i]) > 1e-9 {
+					t.Errorf("%s: harmonic %d = %v, want %v", tt.name, k+1, got[k], want[k])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestDescriptorsInvalid(t *testing.T) {
+	h := hullXY(0, 0, 4, 0, 4, 3, 0, 3)
+	if _, err := h.EllipticFourier(-1); err != ErrInvalidHarmonics {
+		t.Errorf("EllipticFourier(-1): got %v, want ErrInvalidHarmonics", err)
+	}
+	if _, err := h.NormalizedEllipticFourier(-1); err != ErrInvalidHarmonics {
+		t.Errorf("NormalizedEllipticFourier(-1): got %v, want ErrInvalidHarmonics", err)
+	}
+	if es, err := h.EllipticFourier(0); err != nil || len(es) != 0 {
+		t.Errorf("EllipticFourier(0) = %v, %v, want no harmonics", es, err)
+	}
+	if _, err := h.Zernike(-1); err != ErrInvalidOrder {
+		t.Errorf("Zernike(-1): got %v, want ErrInvalidOrder", err)
+	}
+	tests := []struct {
+		name             string
+		h                Hull
+		order, harmonics int
+		want             error
+	}{
+		{"valid", h, 4, 3, nil},
+		{"negative order", h, -1, 3, ErrInvalidOrder},
+		{"negative harmonics", h, 4, -2, ErrInvalidHarmonics},
+		{"empty", HullPs(nil), 4, 3, ErrEmptyHull},
+		{"collinear", hullXY(0, 0, 1, 1, 2, 2), 4, 3, ErrDegenerateHull},
+	}
+	for _, tt := range tests {
+		if _, err := tt.h.Features(tt.order, tt.harmonics); err != tt.want {
+			t.Errorf("Features %s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestZernike(t *testing.T) {
+	// the magnitudes are listed for (n, m) = (0, 0), (1, 1), (2, 0), (2, 2), (3, 1), (3, 3), (4, 0), (4, 2), (4, 4)
+	zs, err := polygonDisk(20, 30, 10, 720).Zernike(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zs) != 9 {
+		t.Fatalf("got %d magnitudes, want 9", len(zs))
+	}
+	// a disk filling the unit disc has |A_00| = 1 and no moment with m > 0
+	if math.Abs(zs[0]-1) > 0.01 {
+		t.Errorf("disk: |A_00| = %v, want 1", zs[0])
+	}
+	for _, i := range []int{1, 3, 4, 5, 7, 8} {
+		if zs[i] > 0.01 {
+			t.Errorf("disk: magnitude %d = %v, want 0", i, zs[i])
+		}
+	}
+
+	h := hullXY(0, 0, 7, 1, 9, 5, 4, 4, 2, 8, -1, 3)
+	want, _ := h.Zernike(4)
+	tests := []struct {
+		name string
+		f    func(geometry.Point) geometry.Point
+		eps  float64
+	}{
+		// a Hull in millimetres is sampled as densely as one in pixels
+		{"scaled to millimetres", func(p geometry.Point) geometry.Point { return p.Scale(0.04) }, 1e-9},
+		{"scaled up", func(p geometry.Point) geometry.Point { return p.Scale(25) }, 1e-9},
+		{"translated", func(p geometry.Point) geometry.Point { return p.Translate(0.3, -8.6) }, 1e-9},
+		{"rotated", func(p geometry.Point) geometry.Point { return p.Rotate(0.7) }, 0.005},
+	}
+	for _, tt := range tests {
+		got, err := h.Map(tt.f).Zernike(4)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > tt.eps {
+				t.Errorf("%s: magnitude %d = %v, want %v", tt.name, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	h := hullXY(0, 0, 7, 1, 9, 5, 4, 4, 2, 8, -1, 3)
+	const order, harmonics = 4, 3
+	want, err := h.Features(order, harmonics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// seven Hu invariants, eccentricity, solidity, circularity, nine Zernike magnitudes and four coefficients per harmonic
+	if len(want) != 7+3+9+4*harmonics {
+		t.Fatalf("got %d features, want %d", len(want), 7+3+9+4*harmonics)
+	}
+	if solidity, _ := h.Solidity(); want[8] != solidity || want[7] != h.Moments().Eccentricity() || want[9] != h.Circularity() {
+		t.Errorf("features 7 to 9 = %v, want the eccentricity, solidity and circularity", want[7:10])
+	}
+	// the features of the same shape in millimetres, rotated and moved agree up to the sampling of the Zernike moments
+	calibrated := h.Map(func(p geometry.Point) geometry.Point { return p.Rotate(1.2).Scale(0.04).Translate(3, 1) })
+	got, err := calibrated.Features(order, harmonics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 0.005*math.Max(1, math.Abs(want[i])) {
+			t.Errorf("feature %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
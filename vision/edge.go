@@ -0,0 +1,220 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"screwSort/geometry"
+)
+
+// Gradient describes the horizontal and vertical derivatives of an image at every pixel in value per pixel
+type Gradient struct {
+	rect   image.Rectangle
+	gx, gy []float64
+}
+
+// SobelGradient returns the Gradient of the image found with the 3×3 Sobel kernels
+//
+// Pixels beyond the border take the value of the nearest border pixel
+func SobelGradient(im *image.Gray, ws ...Workers) Gradient {
+	return gradient(im.Rect, grayValues(im), [3]float64{1, 2, 1}, workersOf(ws))
+}
+
+// ScharrGradient returns the Gradient of the image found with the 3×3 Scharr kernels,
+// which are more rotationally symmetric than the Sobel kernels
+//
+// Pixels beyond the border take the value of the nearest border pixel
+func ScharrGradient(im *image.Gray, ws ...Workers) Gradient {
+	return gradient(im.Rect, grayValues(im), [3]float64{3, 10, 3}, workersOf(ws))
+}
+
+// gradient convolves the values with the central difference along one axis and the smoothing kernel along the other,
+// normalized so that the result is a derivative
+func gradient(rect image.Rectangle, vs []float64, ks [3]float64, ws Workers) Gradient {
+	dx, dy := rect.Dx(), rect.Dy()
+	norm := 2 * (ks[0] + ks[1] + ks[2])
+	gx, gy := make([]float64, len(vs)), make([]float64, len(vs))
+	at := func(x, y int) float64 {
+		return vs[clamp(y, 0, dy-1)*dx+clamp(x, 0, dx-1)]
+	}
+	ws.parallelRows(dy, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < dx; x++ {
+				var sx, sy float64
+				for i, k := range ks {
+					sx += k * (at(x+1, y+i-1) - at(x-1, y+i-1))
+					sy += k * (at(x+i-1, y+1) - at(x+i-1, y-1))
+				}
+				gx[y*dx+x], gy[y*dx+x] = sx/norm, sy/norm
+			}
+		}
+	})
+	return Gradient{rect, gx, gy}
+}
+
+// Bounds returns the bounds of the Gradient
+func (g Gradient) Bounds() image.Rectangle {
+	return g.rect
+}
+
+// At returns the horizontal and vertical derivatives at the pixel
+func (g Gradient) At(x, y int) (float64, float64) {
+	i := (y-g.rect.Min.Y)*g.rect.Dx() + x - g.rect.Min.X
+	return g.gx[i], g.gy[i]
+}
+
+// Magnitude returns the Surface of the gradient magnitude
+func (g Gradient) Magnitude() Surface {
+	vs := make([]float64, len(g.gx))
+	for i := range vs {
+		vs[i] = math.Hypot(g.gx[i], g.gy[i])
+	}
+	return Surface{g.rect, vs}
+}
+
+// Direction returns the Surface of the gradient angle clockwise from +x, pointing from dark to bright
+func (g Gradient) Direction() Surface {
+	vs := make([]float64, len(g.gx))
+	for i := range vs {
+		vs[i] = math.Atan2(g.gy[i], g.gx[i])
+	}
+	return Surface{g.rect, vs}
+}
+
+// Edgel describes a point on an edge located to sub-pixel precision along the gradient
+type Edgel struct {
+	p         geometry.Point
+	angle     float64
+	magnitude float64
+}
+
+// Point returns the location of the Edgel
+func (e Edgel) Point() geometry.Point {
+	return e.p
+}
+
+// Angle returns the gradient angle at the Edgel clockwise from +x, pointing from dark to bright
+func (e Edgel) Angle() float64 {
+	return e.angle
+}
+
+// Magnitude returns the gradient magnitude at the Edgel
+func (e Edgel) Magnitude() float64 {
+	return e.magnitude
+}
+
+// Canny returns the binary image of the edges of the image after smoothing with a Gaussian of standard deviation
+// sigma, thinning the Sobel gradient magnitude by non-maximum suppression, and keeping the pixels above high
+// together with those above low that are connected to them
+//
+// The thresholds are in value per pixel
+func Canny(im *image.Gray, sigma, low, high float64, ws ...Workers) (*image.Gray, error) {
+	g, edges, err := canny(im, sigma, low, high, workersOf(ws))
+	if err != nil {
+		return nil, err
+	}
+	out := image.NewGray(im.Rect)
+	dx := g.rect.Dx()
+	for i, e := range edges {
+		if e {
+			out.Pix[(i/dx)*out.Stride+i%dx] = w
+		}
+	}
+	return out, nil
+}
+
+// CannyEdgels returns the Edgels of the Canny edges of the image, each moved from its pixel center to the peak
+// of the parabola through the gradient magnitudes of the pixel and its two neighbors across the edge
+func CannyEdgels(im *image.Gray, sigma, low, high float64, ws ...Workers) ([]Edgel, error) {
+	g, edges, err := canny(im, sigma, low, high, workersOf(ws))
+	if err != nil {
+		return nil, err
+	}
+	dx, dy := g.rect.Dx(), g.rect.Dy()
+	mag := g.Magnitude()
+	var es []Edgel
+	for i, e := range edges {
+		if !e {
+			continue
+		}
+		x, y := i%dx, i/dx
+		ox, oy := gradientNeighbor(g.gx[i], g.gy[i])
+		m0, mm, mp := mag.vs[i], mag.vs[clamp(y-oy, 0, dy-1)*dx+clamp(x-ox, 0, dx-1)], mag.vs[clamp(y+oy, 0, dy-1)*dx+clamp(x+ox, 0, dx-1)]
+		t := 0.
+		if d := mm - 2*m0 + mp; d < 0 {
+			t = math.Max(-0.5, math.Min(0.5, (mm-mp)/(2*d)))
+		}
+		p := geometry.PointImage(x+g.rect.Min.X, y+g.rect.Min.Y)
+		es = append(es, Edgel{
+			geometry.PointXY(p.X()+t*float64(ox), p.Y()+t*float64(oy)),
+			math.Atan2(g.gy[i], g.gx[i]),
+			m0 - (mm-mp)*t/4,
+		})
+	}
+	return es, nil
+}
+
+// canny returns the Gradient of the smoothed image and whether each pixel is a Canny edge
+func canny(im *image.Gray, sigma, low, high float64, ws Workers) (Gradient, []bool, error) {
+	if low < 0 || low > high {
+		return Gradient{}, nil, ErrInvalidHysteresis
+	}
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	g := gradient(im.Rect, gaussianValues(im, sigma, ws), [3]float64{1, 2, 1}, ws)
+	mag := g.Magnitude()
+
+	// suppress pixels that are not the maximum across the edge
+	strong, weak := make([]bool, len(mag.vs)), make([]bool, len(mag.vs))
+	var stack []int
+	ws.parallelRows(dy, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < dx; x++ {
+				i := y*dx + x
+				m := mag.vs[i]
+				if m < low || m == 0 {
+					continue
+				}
+				ox, oy := gradientNeighbor(g.gx[i], g.gy[i])
+				mm, mp := mag.vs[clamp(y-oy, 0, dy-1)*dx+clamp(x-ox, 0, dx-1)], mag.vs[clamp(y+oy, 0, dy-1)*dx+clamp(x+ox, 0, dx-1)]
+				if m < mm || m <= mp {
+					continue
+				}
+				if m >= high {
+					strong[i] = true
+				} else {
+					weak[i] = true
+				}
+			}
+		}
+	})
+	for i, e := range strong {
+		if e {
+			stack = append(stack, i)
+		}
+	}
+
+	// grow the strong edges through the connected weak edges
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := i%dx, i/dx
+		for ny := y - 1; ny <= y+1; ny++ {
+			for nx := x - 1; nx <= x+1; nx++ {
+				if nx < 0 || ny < 0 || nx >= dx || ny >= dy {
+					continue
+				}
+				if j := ny*dx + nx; weak[j] {
+					weak[j], strong[j] = false, true
+					stack = append(stack, j)
+				}
+			}
+		}
+	}
+	return g, strong, nil
+}
+
+// gradientNeighbor returns the offset to the neighboring pixel closest to the gradient direction
+func gradientNeighbor(gx, gy float64) (int, int) {
+	a := math.Atan2(gy, gx)
+	o := [4][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}}[(int(math.Round(a/(math.Pi/4)))+8)%4]
+	return o[0], o[1]
+}
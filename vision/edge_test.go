@@ -0,0 +1,119 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// valueImage returns the image of size dx × dy whose pixels take the values of the function rounded and clamped
+func valueImage(dx, dy int, f func(x, y int) float64) *image.Gray {
+	im := image.NewGray(image.Rect(0, 0, dx, dy))
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			im.Pix[y*im.Stride+x] = clampValue(f(x, y))
+		}
+	}
+	return im
+}
+
+func TestGradientStepAndRamp(t *testing.T) {
+	const dark, bright = 50, 200
+	tests := []struct {
+		name string
+		im   *image.Gray
+		// the pixels away from the border where the gradient is expected, and the gradient there
+		in     func(x, y int) bool
+		gx, gy float64
+	}{
+		// a step between columns 4 and 5 has a central difference of half the step on either side of it
+		{"step to the right", valueImage(10, 8, func(x, _ int) float64 { return map[bool]float64{true: dark, false: bright}[x < 5] }),
+			func(x, _ int) bool { return x == 4 || x == 5 }, (bright - dark) / 2, 0},
+		{"step to the left", valueImage(10, 8, func(x, _ int) float64 { return map[bool]float64{true: bright, false: dark}[x < 5] }),
+			func(x, _ int) bool { return x == 4 || x == 5 }, -(bright - dark) / 2, 0},
+		{"step down", valueImage(8, 10, func(_, y int) float64 { return map[bool]float64{true: dark, false: bright}[y < 5] }),
+			func(_, y int) bool { return y == 4 || y == 5 }, 0, (bright - dark) / 2},
+		// both kernels differentiate a linear ramp exactly
+		{"horizontal ramp", valueImage(10, 8, func(x, _ int) float64 { return 20 + 12*float64(x) }),
+			func(x, _ int) bool { return true }, 12, 0},
+		{"diagonal ramp", valueImage(10, 10, func(x, y int) float64 { return 20 + 10*float64(x) + 6*float64(y) }),
+			func(x, y int) bool { return true }, 10, 6},
+		{"upward ramp", valueImage(10, 10, func(x, y int) float64 { return 200 - 5*float64(x) - 15*float64(y) }),
+			func(x, y int) bool { return true }, -5, -15},
+	}
+	const eps = 1e-9
+	for _, tt := range tests {
+		for name, g := range map[string]Gradient{"SobelGradient": SobelGradient(tt.im), "ScharrGradient": ScharrGradient(tt.im)} {
+			if g.Bounds() != tt.im.Rect {
+				t.Errorf("%s %s: Bounds() = %v, want %v", name, tt.name, g.Bounds(), tt.im.Rect)
+			}
+			mag, dir := g.Magnitude(), g.Direction()
+			r := tt.im.Rect.Inset(1)
+		pixels:
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					wx, wy := 0., 0.
+					if tt.in(x, y) {
+						wx, wy = tt.gx, tt.gy
+					}
+					gx, gy := g.At(x, y)
+					if math.Abs(gx-wx) > eps || math.Abs(gy-wy) > eps {
+						t.Errorf("%s %s: At(%d, %d) = %v, %v, want %v, %v", name, tt.name, x, y, gx, gy, wx, wy)
+						break pixels
+					}
+					if m := mag.At(x, y); math.Abs(m-math.Hypot(wx, wy)) > eps {
+						t.Errorf("%s %s: magnitude at (%d, %d) = %v, want %v", name, tt.name, x, y, m, math.Hypot(wx, wy))
+						break pixels
+					}
+					// the direction points from dark to bright, clockwise from +x on screen
+					if a := dir.At(x, y); (wx != 0 || wy != 0) && math.Abs(a-math.Atan2(wy, wx)) > eps {
+						t.Errorf("%s %s: direction at (%d, %d) = %v, want %v", name, tt.name, x, y, a, math.Atan2(wy, wx))
+						break pixels
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCannyEdgelsSubPixel(t *testing.T) {
+	const dark, bright, sigma = 40, 220, 1.5
+	// the pixel cut by the edge takes the mean of the values on either side weighted by area,
+	// and the parabola through the smoothed magnitudes recovers the edge between pixel centers
+	step := func(v, edge float64) float64 {
+		f := math.Max(0, math.Min(1, v+1-edge))
+		return dark + f*(bright-dark)
+	}
+	for _, edge := range []float64{15, 15.25, 15.5, 15.7} {
+		tests := []struct {
+			name  string
+			im    *image.Gray
+			at    func(e Edgel) float64
+			angle float64
+		}{
+			{"vertical", valueImage(30, 12, func(x, _ int) float64 { return step(float64(x), edge) }),
+				func(e Edgel) float64 { return e.Point().X() }, 0},
+			{"horizontal", valueImage(12, 30, func(_, y int) float64 { return step(float64(y), edge) }),
+				func(e Edgel) float64 { return e.Point().Y() }, math.Pi / 2},
+			{"mirrored", valueImage(30, 12, func(x, _ int) float64 { return step(float64(29-x), 30-edge) }),
+				func(e Edgel) float64 { return e.Point().X() }, math.Pi},
+		}
+		for _, tt := range tests {
+			es, err := CannyEdgels(tt.im, sigma, 10, 30)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(es) != 12 {
+				t.Errorf("edge at %v %s: got %d Edgels, want one per row", edge, tt.name, len(es))
+			}
+			for _, e := range es {
+				if p := tt.at(e); math.Abs(p-edge) > 0.01 {
+					t.Errorf("edge at %v %s: Edgel at %v", edge, tt.name, p)
+				}
+				if math.Abs(e.Angle()-tt.angle) > 1e-9 {
+					t.Errorf("edge at %v %s: Angle() = %v, want %v", edge, tt.name, e.Angle(), tt.angle)
+				}
+			}
+		}
+	}
+}
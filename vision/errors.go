@@ -0,0 +1,36 @@
+package vision
+
+import "errors"
+
+var (
+	// ErrEmptyHull is returned when an operation requires a Hull with points
+	ErrEmptyHull = errors.New("vision: hull has no points")
+	// ErrDegenerateHull is returned when the points of a Hull do not span an area
+	ErrDegenerateHull = errors.New("vision: hull points are collinear")
+	// ErrInvalidWindow is returned when a window or crop rectangle is empty or reversed
+	ErrInvalidWindow = errors.New("vision: failed to satisfy pMin.X < pMax.X, pMin.Y < pMax.Y")
+	// ErrInvalidStrength is returned when a neighbor-count strength is outside {0, ..., 7}
+	ErrInvalidStrength = errors.New("vision: failed to satisfy strength ∈ {0, ..., 7}")
+	// ErrInvalidLevels is returned when the number of thresholds is outside {1, ..., 255}
+	ErrInvalidLevels = errors.New("vision: failed to satisfy n ∈ {1, ..., 255}")
+	// ErrInvalidOrder is returned when the order of the Zernike moments is negative
+	ErrInvalidOrder = errors.New("vision: failed to satisfy order ≥ 0")
+	// ErrInvalidHarmonics is returned when the number of elliptic Fourier harmonics is negative
+	ErrInvalidHarmonics = errors.New("vision: failed to satisfy n ≥ 0")
+	// ErrInvalidHysteresis is returned when the hysteresis thresholds are negative or reversed
+	ErrInvalidHysteresis = errors.New("vision: failed to satisfy 0 ≤ low ≤ high")
+	// ErrInvalidRadius is returned when a window radius is too small for the operation
+	ErrInvalidRadius = errors.New("vision: window radius is out of range")
+	// ErrInvalidSigma is returned when the standard deviation of a smoothing kernel is not positive
+	ErrInvalidSigma = errors.New("vision: failed to satisfy sigma > 0")
+	// ErrInvalidRange is returned when the dynamic range of the standard deviation is not positive
+	ErrInvalidRange = errors.New("vision: failed to satisfy sr > 0")
+	// ErrInvalidScale is returned when a scale factor is not positive and finite or leaves an image without pixels
+	ErrInvalidScale = errors.New("vision: failed to satisfy 0 < f < ∞")
+	// ErrBoundsMismatch is returned when a Surface or Labels does not cover the same bounds as the image
+	ErrBoundsMismatch = errors.New("vision: bounds differ from those of the image")
+	// ErrSingularTransform is returned when an affine transform cannot be inverted
+	ErrSingularTransform = errors.New("vision: affine transform is singular")
+	// ErrNilImage is returned when a nil image is passed to be saved
+	ErrNilImage = errors.New("vision: image is nil")
+)
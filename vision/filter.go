@@ -2,7 +2,6 @@ package vision
 
 import (
 	"image"
-	"image/color"
 	"screwSort/geometry"
 	"screwSort/utility"
 )
@@ -12,147 +11,191 @@ const (
 	w uint8 = 255
 )
 
-func Threshold(im *image.Gray, t uint8) *image.Gray {
-	out := image.NewGray(im.Rect)
-	for y := 0; y < im.Rect.Dy(); y++ {
-		for x := 0; x < im.Rect.Dx(); x++ {
-			v := im.GrayAt(x, y).Y
-			if v >= t {
-				out.Set(x, y, color.Gray{Y: w})
-			} else {
-				out.Set(x, y, color.Gray{Y: b})
-			}
-		}
+func Threshold(im *image.Gray, t uint8, ws ...Workers) *image.Gray {
+	var lut [256]uint8
+	for v := int(t); v < 256; v++ {
+		lut[v] = w
 	}
-	return out
+	return mapGray(im, &lut, workersOf(ws))
 }
 
-func InverseThreshold(im *image.Gray, t uint8) *image.Gray {
-	out := image.NewGray(im.Rect)
-	for y := im.Rect.Min.Y; y < im.Rect.Max.Y; y++ {
-		for x := im.Rect.Min.X; x < im.Rect.Max.X; x++ {
-			v := im.GrayAt(x, y).Y
-			if v <= t {
-				out.Set(x, y, color.Gray{Y: w})
-			} else {
-				out.Set(x, y, color.Gray{Y: b})
-			}
-		}
+func InverseThreshold(im *image.Gray, t uint8, ws ...Workers) *image.Gray {
+	var lut [256]uint8
+	for v := 0; v <= int(t); v++ {
+		lut[v] = w
 	}
-	return out
+	return mapGray(im, &lut, workersOf(ws))
+}
+
+func Invert(im *image.Gray, ws ...Workers) *image.Gray {
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = w - uint8(v)
+	}
+	return mapGray(im, &lut, workersOf(ws))
 }
 
-func Invert(im *image.Gray) *image.Gray {
+// mapGray returns the image with every pixel value replaced through the lookup table
+func mapGray(im *image.Gray, lut *[256]uint8, ws Workers) *image.Gray {
 	out := image.NewGray(im.Rect)
-	for y := 0; y < im.Rect.Dy(); y++ {
-		for x := 0; x < im.Rect.Dx(); x++ {
-			v := im.GrayAt(x, y).Y
-			out.Set(x, y, color.Gray{Y: w - v})
+	dx := im.Rect.Dx()
+	ws.parallelRows(im.Rect.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row, outRow := im.Pix[y*im.Stride:y*im.Stride+dx], out.Pix[y*out.Stride:y*out.Stride+dx]
+			for x, v := range row {
+				outRow[x] = lut[v]
+			}
 		}
-	}
+	})
 	return out
 }
 
-func ScaleNearestNeighbor(im *image.Gray, f float64) *image.Gray {
+func ScaleNearestNeighbor(im *image.Gray, f float64, ws ...Workers) *image.Gray {
 	out := BlackGray(utility.IntRound(f*float64(im.Rect.Dx())), utility.IntRound(f*float64(im.Rect.Dy())))
 	rx, ry := float64(im.Rect.Dx())/float64(out.Rect.Dx()), float64(im.Rect.Dy())/float64(out.Rect.Dy())
-	for y := 0; y < out.Rect.Dy(); y++ {
-		for x := 0; x < out.Rect.Dx(); x++ {
-			out.Set(x, y, im.At(utility.IntRound(rx*float64(x)+(rx-1)/2), utility.IntRound(ry*float64(y)+(ry-1)/2)))
-		}
+	xs := make([]int, out.Rect.Dx())
+	for x := range xs {
+		xs[x] = clamp(utility.IntRound(rx*float64(x)+(rx-1)/2), 0, im.Rect.Dx()-1)
 	}
+	workersOf(ws).parallelRows(out.Rect.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row := im.Pix[clamp(utility.IntRound(ry*float64(y)+(ry-1)/2), 0, im.Rect.Dy()-1)*im.Stride:]
+			outRow := out.Pix[y*out.Stride:]
+			for x, sx := range xs {
+				outRow[x] = row[sx]
+			}
+		}
+	})
 	return out
 }
 
-func Crop(im *image.Gray, pMin, pMax image.Point) *image.Gray {
+func Crop(im *image.Gray, pMin, pMax image.Point) (*image.Gray, error) {
 	if pMin.X >= pMax.X || pMin.Y >= pMax.Y {
-		panic("failed to satisfy pMin.X < pMax.X, pMin.Y < pMax.Y")
+		return nil, ErrInvalidWindow
 	}
 	out := image.NewGray(image.Rectangle{
 		Min: image.Point{},
 		Max: image.Point{X: pMax.X - pMin.X + 1, Y: pMax.Y - pMin.Y + 1},
 	})
-	for y := 0; y < out.Rect.Dy(); y++ {
-		for x := 0; x < out.Rect.Dx(); x++ {
-			out.Set(x, y, im.GrayAt(pMin.X+x, pMin.Y+y))
-		}
+	r := out.Rect.Add(pMin).Intersect(im.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		copy(out.Pix[out.PixOffset(r.Min.X-pMin.X, y-pMin.Y):], im.Pix[im.PixOffset(r.Min.X, y):im.PixOffset(r.Max.X, y)])
+	}
+	return out, nil
+}
+
+// Pad returns a copy of the image surrounded by a border of n pixels with the value v
+func Pad(im *image.Gray, n int, v uint8) *image.Gray {
+	out := image.NewGray(image.Rect(0, 0, im.Rect.Dx()+2*n, im.Rect.Dy()+2*n))
+	for i := range out.Pix {
+		out.Pix[i] = v
+	}
+	for y := im.Rect.Min.Y; y < im.Rect.Max.Y; y++ {
+		copy(out.Pix[out.PixOffset(n, n+y-im.Rect.Min.Y):], im.Pix[im.PixOffset(im.Rect.Min.X, y):im.PixOffset(im.Rect.Max.X, y)])
 	}
 	return out
 }
 
-func Erode(im *image.Gray, strength uint8) *image.Gray {
+func Erode(im *image.Gray, strength uint8, ws ...Workers) (*image.Gray, error) {
 	if strength > 7 {
-		panic("failed to satisfy strength ∈ {0, ..., 7}")
+		return nil, ErrInvalidStrength
 	}
-	out := image.NewGray(im.Rect)
-
-	for y := im.Rect.Min.Y + 1; y < im.Rect.Max.Y-1; y++ {
-		for x := im.Rect.Min.X + 1; x < im.Rect.Max.X-1; x++ {
-			v := im.GrayAt(x, y).Y
-			neighborCount := -(im.GrayAt(x-1, y-1).Y + im.GrayAt(x, y-1).Y + im.GrayAt(x+1, y-1).Y + im.GrayAt(x+1, y).Y + im.GrayAt(x+1, y+1).Y + im.GrayAt(x, y+1).Y + im.GrayAt(x-1, y+1).Y + im.GrayAt(x-1, y).Y)
-			if neighborCount <= strength {
-				v = b
-			}
-			out.Set(x, y, color.Gray{Y: v})
+	return mapNeighbors(im, func(v, neighborCount uint8) uint8 {
+		if neighborCount <= strength {
+			return b
 		}
-	}
-	return out
+		return v
+	}, workersOf(ws)), nil
 }
 
-func ErodeN(im *image.Gray, strength uint8, n int) *image.Gray {
+func ErodeN(im *image.Gray, strength uint8, n int, ws ...Workers) (*image.Gray, error) {
+	var err error
 	for i := 0; i < n; i++ {
-		im = Erode(im, strength)
+		if im, err = Erode(im, strength, ws...); err != nil {
+			return nil, err
+		}
 	}
-	return im
+	return im, nil
 }
 
-func Dilate(im *image.Gray, strength uint8) *image.Gray {
+func Dilate(im *image.Gray, strength uint8, ws ...Workers) (*image.Gray, error) {
 	if strength > 7 {
-		panic("failed to satisfy strength ∈ {0, ..., 7}")
+		return nil, ErrInvalidStrength
 	}
-	out := image.NewGray(im.Rect)
-
-	for y := im.Rect.Min.Y + 1; y < im.Rect.Max.Y-1; y++ {
-		for x := im.Rect.Min.X + 1; x < im.Rect.Max.X-1; x++ {
-			v := im.GrayAt(x, y).Y
-			neighborCount := -(im.GrayAt(x-1, y-1).Y + im.GrayAt(x, y-1).Y + im.GrayAt(x+1, y-1).Y + im.GrayAt(x+1, y).Y + im.GrayAt(x+1, y+1).Y + im.GrayAt(x, y+1).Y + im.GrayAt(x-1, y+1).Y + im.GrayAt(x-1, y).Y)
-			if neighborCount >= 8-strength {
-				v = w
-			}
-			out.Set(x, y, color.Gray{Y: v})
+	return mapNeighbors(im, func(v, neighborCount uint8) uint8 {
+		if neighborCount >= 8-strength {
+			return w
 		}
-	}
-	return out
+		return v
+	}, workersOf(ws)), nil
 }
 
-func DilateN(im *image.Gray, strength uint8, n int) *image.Gray {
+func DilateN(im *image.Gray, strength uint8, n int, ws ...Workers) (*image.Gray, error) {
+	var err error
 	for i := 0; i < n; i++ {
-		im = Dilate(im, strength)
+		if im, err = Dilate(im, strength, ws...); err != nil {
+			return nil, err
+		}
 	}
-	return im
+	return im, nil
 }
 
-func FindEdge(im *image.Gray) *image.Gray {
-	out := image.NewGray(im.Rect)
-	for y := 1; y < im.Rect.Dy()-1; y++ {
-		for x := 1; x < im.Rect.Dx()-1; x++ {
-			neighborCount := -(im.GrayAt(x-1, y-1).Y + im.GrayAt(x, y-1).Y + im.GrayAt(x+1, y-1).Y + im.GrayAt(x+1, y).Y + im.GrayAt(x+1, y+1).Y + im.GrayAt(x, y+1).Y + im.GrayAt(x-1, y+1).Y + im.GrayAt(x-1, y).Y)
-			if 5 <= neighborCount && neighborCount <= 7 {
-				out.Set(x, y, color.Gray{Y: w})
-			} else {
-				out.Set(x, y, color.Gray{Y: b})
-			}
+func FindEdge(im *image.Gray, ws ...Workers) *image.Gray {
+	return mapNeighbors(im, func(_, neighborCount uint8) uint8 {
+		if 5 <= neighborCount && neighborCount <= 7 {
+			return w
+		}
+		return b
+	}, workersOf(ws))
+}
 
+// mapNeighbors returns the image with every pixel replaced by f of its value and the number of its 8 neighbors
+// that are not black, counting pixels outside the image as black
+//
+// f is tabulated once, and the neighbors are counted from the sums of the columns of three rows
+func mapNeighbors(im *image.Gray, f func(v, neighborCount uint8) uint8, ws Workers) *image.Gray {
+	var lut [9][256]uint8
+	for n := range lut {
+		for v := range lut[n] {
+			lut[n][v] = f(uint8(v), uint8(n))
 		}
 	}
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	out := image.NewGray(im.Rect)
+	ws.parallelRows(dy, func(y0, y1 int) {
+		// the sums are padded by a zero column on either side
+		sums := make([]uint8, dx+2)
+		for y := y0; y < y1; y++ {
+			for x := range sums {
+				sums[x] = 0
+			}
+			for j := utility.Max(y-1, 0); j <= utility.Min(y+1, dy-1); j++ {
+				for x, v := range im.Pix[j*im.Stride : j*im.Stride+dx] {
+					if v != b {
+						sums[x+1]++
+					}
+				}
+			}
+			row := im.Pix[y*im.Stride : y*im.Stride+dx]
+			outRow := out.Pix[y*out.Stride : y*out.Stride+dx]
+			for x, v := range row {
+				n := sums[x] + sums[x+1] + sums[x+2]
+				if v != b {
+					n--
+				}
+				outRow[x] = lut[n][v]
+			}
+		}
+	})
 	return out
 }
 
 func NonzeroPoints(im *image.Gray) (ps []geometry.Point) {
 	for y := 0; y < im.Rect.Dy(); y++ {
-		for x := 0; x < im.Rect.Dx(); x++ {
-			if im.GrayAt(x, y).Y != b {
-				ps = append(ps, geometry.PointImage(x, y))
+		row := im.Pix[y*im.Stride : y*im.Stride+im.Rect.Dx()]
+		for x, v := range row {
+			if v != b {
+				ps = append(ps, geometry.PointImage(x+im.Rect.Min.X, y+im.Rect.Min.Y))
 			}
 		}
 	}
@@ -0,0 +1,138 @@
+package vision
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// thresholdGeneric thresholds the image through At and Set
+func thresholdGeneric(im *image.Gray, t uint8) *image.Gray {
+	out := image.NewGray(im.Rect)
+	for y := im.Rect.Min.Y; y < im.Rect.Max.Y; y++ {
+		for x := im.Rect.Min.X; x < im.Rect.Max.X; x++ {
+			if im.GrayAt(x, y).Y >= t {
+				out.SetGray(x, y, color.Gray{Y: w})
+			}
+		}
+	}
+	return out
+}
+
+// mapNeighborsGeneric counts the white neighbors of every pixel through At and Set
+func mapNeighborsGeneric(im *image.Gray, f func(v, neighborCount uint8) uint8) *image.Gray {
+	out := image.NewGray(im.Rect)
+	for y := im.Rect.Min.Y; y < im.Rect.Max.Y; y++ {
+		for x := im.Rect.Min.X; x < im.Rect.Max.X; x++ {
+			var n uint8
+			for j := -1; j <= 1; j++ {
+				for i := -1; i <= 1; i++ {
+					p := image.Point{X: x + i, Y: y + j}
+					if (i != 0 || j != 0) && p.In(im.Rect) && im.GrayAt(p.X, p.Y).Y != b {
+						n++
+					}
+				}
+			}
+			out.SetGray(x, y, color.Gray{Y: f(im.GrayAt(x, y).Y, n)})
+		}
+	}
+	return out
+}
+
+func erodeGeneric(im *image.Gray, strength uint8) *image.Gray {
+	return mapNeighborsGeneric(im, func(v, n uint8) uint8 {
+		if n <= strength {
+			return b
+		}
+		return v
+	})
+}
+
+func dilateGeneric(im *image.Gray, strength uint8) *image.Gray {
+	return mapNeighborsGeneric(im, func(v, n uint8) uint8 {
+		if n >= 8-strength {
+			return w
+		}
+		return v
+	})
+}
+
+// testBinaryImages returns the thresholded gray version of every test image
+func testBinaryImages(dx, dy int) map[string]*image.Gray {
+	out := make(map[string]*image.Gray)
+	for name, im := range testImages(dx, dy) {
+		out[name] = Threshold(ToGray(im), 128)
+	}
+	return out
+}
+
+func TestFiltersMatchGeneric(t *testing.T) {
+	for name, im := range testImages(37, 23) {
+		g := ToGray(im)
+		if got, want := Threshold(g, 100), thresholdGeneric(g, 100); !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("Threshold(%s) differs from the generic filter", name)
+		}
+	}
+	for name, im := range testBinaryImages(37, 23) {
+		for strength := uint8(0); strength <= 7; strength++ {
+			got, err := Erode(im, strength)
+			if err != nil || !bytes.Equal(got.Pix, erodeGeneric(im, strength).Pix) {
+				t.Errorf("Erode(%s, %d) differs from the generic filter: %v", name, strength, err)
+			}
+			got, err = Dilate(im, strength)
+			if err != nil || !bytes.Equal(got.Pix, dilateGeneric(im, strength).Pix) {
+				t.Errorf("Dilate(%s, %d) differs from the generic filter: %v", name, strength, err)
+			}
+		}
+	}
+	if _, err := Erode(image.NewGray(image.Rect(0, 0, 1, 1)), 8); err != ErrInvalidStrength {
+		t.Errorf("Erode with strength 8: got %v, want ErrInvalidStrength", err)
+	}
+}
+
+func BenchmarkThreshold(b *testing.B) {
+	for name, im := range testImages(1024, 768) {
+		g := ToGray(im)
+		b.Run(name+"/Pix", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Threshold(g, 128)
+			}
+		})
+		b.Run(name+"/Generic", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				thresholdGeneric(g, 128)
+			}
+		})
+	}
+}
+
+func BenchmarkErode(b *testing.B) {
+	for name, im := range testBinaryImages(1024, 768) {
+		b.Run(name+"/Pix", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Erode(im, 3)
+			}
+		})
+		b.Run(name+"/Generic", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				erodeGeneric(im, 3)
+			}
+		})
+	}
+}
+
+func BenchmarkDilate(b *testing.B) {
+	for name, im := range testBinaryImages(1024, 768) {
+		b.Run(name+"/Pix", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Dilate(im, 3)
+			}
+		})
+		b.Run(name+"/Generic", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dilateGeneric(im, 3)
+			}
+		})
+	}
+}
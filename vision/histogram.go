@@ -0,0 +1,166 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"screwSort/utility"
+)
+
+// AutoLevel may be passed as the iso-level to SuperHulls to select it per image with OtsuThreshold
+const AutoLevel = -1.
+
+// Histogram returns the number of pixels of the image at each of the 256 gray levels
+func Histogram(im *image.Gray) []int {
+	hist := make([]int, 256)
+	for y := 0; y < im.Rect.Dy(); y++ {
+		for _, v := range im.Pix[y*im.Stride : y*im.Stride+im.Rect.Dx()] {
+			hist[v]++
+		}
+	}
+	return hist
+}
+
+// Histogram16 returns the number of pixels of the 16-bit image at each of the 65536 gray levels
+func Histogram16(im *image.Gray16) []int {
+	hist := make([]int, 1<<16)
+	for y := 0; y < im.Rect.Dy(); y++ {
+		row := im.Pix[y*im.Stride : y*im.Stride+2*im.Rect.Dx()]
+		for i := 0; i < len(row); i += 2 {
+			hist[int(row[i])<<8|int(row[i+1])]++
+		}
+	}
+	return hist
+}
+
+// OtsuThreshold returns the threshold t that maximizes the between-class variance
+// of the pixels with values ≤ t and > t
+func OtsuThreshold(im *image.Gray) uint8 {
+	return multiOtsuThresholds(im, 1)[0]
+}
+
+// OtsuThreshold16 returns the threshold t that maximizes the between-class variance
+// of the pixels of the 16-bit image with values ≤ t and > t
+func OtsuThreshold16(im *image.Gray16) uint16 {
+	hist := Histogram16(im)
+	var p, s float64
+	for i, c := range hist {
+		p += float64(c)
+		s += float64(i * c)
+	}
+	// p0 and s0 are the pixel count and value sum of the levels ≤ t
+	var p0, s0 float64
+	t, fm := 0, math.Inf(-1)
+	for i, c := range hist[:len(hist)-1] {
+		p0 += float64(c)
+		s0 += float64(i * c)
+		f := 0.
+		if p0 > 0 {
+			f += s0 * s0 / p0
+		}
+		if p1 := p - p0; p1 > 0 {
+			f += (s - s0) * (s - s0) / p1
+		}
+		if f > fm {
+			t, fm = i, f
+		}
+	}
+	return uint16(t)
+}
+
+// MultiOtsuThresholds returns the n ascending thresholds that split the histogram into n+1 classes
+// with the maximum between-class variance
+//
+// It returns ErrInvalidLevels if n is not in {1, ..., 255}
+func MultiOtsuThresholds(im *image.Gray, n int) ([]uint8, error) {
+	if n < 1 || n > 255 {
+		return nil, ErrInvalidLevels
+	}
+	return multiOtsuThresholds(im, n), nil
+}
+
+func multiOtsuThresholds(im *image.Gray, n int) []uint8 {
+	hist := Histogram(im)
+
+	// ps[i] and ss[i] are the pixel count and value sum of the levels below i
+	ps, ss := make([]float64, 257), make([]float64, 257)
+	for i, c := range hist {
+		ps[i+1] = ps[i] + float64(c)
+		ss[i+1] = ss[i] + float64(i*c)
+	}
+	score := func(i, j int) float64 {
+		p := ps[j] - ps[i]
+		if p == 0 {
+			return 0
+		}
+		s := ss[j] - ss[i]
+		return s * s / p
+	}
+
+	// fs[k][j] is the best score of splitting the levels below j into k+1 classes,
+	// and cs[k][j] is where the last of those classes starts
+	fs, cs := make([][]float64, n+1), make([][]int, n+1)
+	for k := range fs {
+		fs[k], cs[k] = make([]float64, 257), make([]int, 257)
+		for j := range fs[k] {
+			fs[k][j] = math.Inf(-1)
+		}
+	}
+	for j := 1; j <= 256; j++ {
+		fs[0][j] = score(0, j)
+	}
+	for k := 1; k <= n; k++ {
+		for j := k + 1; j <= 256; j++ {
+			for i := k; i < j; i++ {
+				if f := fs[k-1][i] + score(i, j); f > fs[k][j] {
+					fs[k][j], cs[k][j] = f, i
+				}
+			}
+		}
+	}
+
+	ts := make([]uint8, n)
+	j := 256
+	for k := n; k > 0; k-- {
+		j = cs[k][j]
+		ts[k-1] = uint8(j - 1)
+	}
+	return ts
+}
+
+// TriangleThreshold returns the threshold found by the triangle method, which suits histograms
+// with a single dominant peak such as a bright background with small dark parts
+func TriangleThreshold(im *image.Gray) uint8 {
+	hist := Histogram(im)
+	lo, hi, peak := -1, -1, 0
+	for i, c := range hist {
+		if c > 0 {
+			if lo < 0 {
+				lo = i
+			}
+			hi = i
+		}
+		if c > hist[peak] {
+			peak = i
+		}
+	}
+	if lo < 0 {
+		return 0
+	}
+
+	end := lo
+	if hi-peak > peak-lo {
+		end = hi
+	}
+	dx, dy := float64(end-peak), float64(hist[end]-hist[peak])
+	t, dm := peak, 0.
+	for i := utility.Min(peak, end); i <= utility.Max(peak, end); i++ {
+		d := math.Abs(dy*float64(i-peak) - dx*float64(hist[i]-hist[peak]))
+		if d > dm {
+			t, dm = i, d
+		}
+	}
+	if end > peak && t > 0 {
+		t--
+	}
+	return uint8(t)
+}
@@ -0,0 +1,118 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gray16Rows returns the 16-bit image with the value dark for every '#' of the rows and light otherwise
+func gray16Rows(dark, light uint16, rows ...string) *image.Gray16 {
+	im := image.NewGray16(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, c := range row {
+			v := light
+			if c == '#' {
+				v = dark
+			}
+			im.SetGray16(x, y, color.Gray16{Y: v})
+		}
+	}
+	return im
+}
+
+func TestOtsuThreshold16(t *testing.T) {
+	rows := []string{
+		"........",
+		"..####..",
+		"..####..",
+		"..####..",
+		"........",
+	}
+	tests := []struct {
+		name        string
+		dark, light uint16
+	}{
+		{"full range", 0, 65535},
+		{"8-bit levels", 40 * 257, 200 * 257},
+		// both levels fall into the same 8-bit bin
+		{"within one 8-bit level", 1000, 1100},
+	}
+	for _, tt := range tests {
+		im := gray16Rows(tt.dark, tt.light, rows...)
+		if th := OtsuThreshold16(im); th < tt.dark || th >= tt.light {
+			t.Errorf("%s: OtsuThreshold16 = %d, want in [%d, %d)", tt.name, th, tt.dark, tt.light)
+		}
+		hs := SuperHulls16(im, AutoLevel, 0)
+		if len(hs) != 1 {
+			t.Errorf("%s: got %d Hulls, want 1", tt.name, len(hs))
+			continue
+		}
+		// the contour lies between the centers of the pixels of the block and those of the pixels around it
+		if a := hs[0].Area(); a < 5 || a > 20 {
+			t.Errorf("%s: Hull area = %v, want in [5, 20]", tt.name, a)
+		}
+	}
+}
+
+// levelImage returns a single-row image with count pixels at each value of the pairs of value and count
+func levelImage(pairs ...int) *image.Gray {
+	var pix []uint8
+	for i := 0; i < len(pairs); i += 2 {
+		for k := 0; k < pairs[i+1]; k++ {
+			pix = append(pix, uint8(pairs[i]))
+		}
+	}
+	im := image.NewGray(image.Rect(0, 0, len(pix), 1))
+	copy(im.Pix, pix)
+	return im
+}
+
+func TestOtsuThreshold(t *testing.T) {
+	tests := []struct {
+		name   string
+		im     *image.Gray
+		lo, hi uint8
+	}{
+		{"two levels", levelImage(40, 50, 200, 50), 40, 199},
+		{"unbalanced levels", levelImage(30, 90, 220, 10), 30, 219},
+		{"two clusters", levelImage(20, 10, 25, 20, 30, 10, 180, 10, 185, 20, 190, 10), 30, 179},
+		// the heavy peak is split from the spread of darker values
+		{"wide and narrow clusters", levelImage(0, 10, 40, 10, 80, 10, 120, 10, 160, 40), 80, 159},
+	}
+	for _, tt := range tests {
+		if got := OtsuThreshold(tt.im); got < tt.lo || got > tt.hi {
+			t.Errorf("%s: OtsuThreshold = %d, want in [%d, %d]", tt.name, got, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestMultiOtsuThresholds(t *testing.T) {
+	im := levelImage(10, 30, 100, 30, 240, 30)
+	ts, err := MultiOtsuThresholds(im, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 2 || ts[0] < 10 || ts[0] >= 100 || ts[1] < 100 || ts[1] >= 240 {
+		t.Errorf("MultiOtsuThresholds = %v, want one in [10, 100) and one in [100, 240)", ts)
+	}
+	if ts, _ := MultiOtsuThresholds(levelImage(40, 50, 200, 50), 1); ts[0] != OtsuThreshold(levelImage(40, 50, 200, 50)) {
+		t.Errorf("MultiOtsuThresholds with n = 1 differs from OtsuThreshold")
+	}
+	for _, n := range []int{0, -1, 256} {
+		if _, err := MultiOtsuThresholds(im, n); err != ErrInvalidLevels {
+			t.Errorf("MultiOtsuThresholds with n = %d: got %v, want ErrInvalidLevels", n, err)
+		}
+	}
+}
+
+func TestTriangleThreshold(t *testing.T) {
+	// a bright background peak with a long tail of darker pixels
+	im := levelImage(20, 2, 40, 3, 60, 4, 80, 5, 100, 6, 220, 200, 230, 50)
+	if got := TriangleThreshold(im); got < 100 || got >= 220 {
+		t.Errorf("TriangleThreshold = %d, want in [100, 220)", got)
+	}
+	if got := TriangleThreshold(image.NewGray(image.Rect(0, 0, 0, 0))); got != 0 {
+		t.Errorf("TriangleThreshold of an empty image = %d, want 0", got)
+	}
+}
@@ -6,47 +6,140 @@ import (
 	"math"
 	"screwSort/fit"
 	"screwSort/geometry"
-	"sort"
 )
 
-const (
-	thresholdArea = 100.
-)
+// AreaMin is the default minimum area in pixels² below which extracted hulls are discarded
+const AreaMin = 100.
 
 type Hull struct {
-	ps   []geometry.Point
-	isCW bool
+	ps        []geometry.Point
+	isCW      bool
+	truncated bool
 }
 
 func HullPs(ps []geometry.Point) Hull {
-	a := 0.
-	n := len(ps)
-	for i, p := range ps {
-		a += p.AngleTo(ps[(i+1)%n])
-	}
-	return Hull{ps, true}
+	h := Hull{ps: ps}
+	h.isCW = h.SignedArea() > 0
+	return h
 }
 
 func (h Hull) Ps() []geometry.Point {
 	return h.ps
 }
 
+// IsCW returns whether the Hull winds clockwise, as holes traced by Hulls and SuperHulls do,
+// and false if it winds counterclockwise, as outer boundaries do, or encloses no area
 func (h Hull) IsCW() bool {
 	return h.isCW
 }
 
-func (h Hull) Area() float64 { // todo: implement
-	return float64(len(h.ps))
+// Reverse returns a new Hull through the same points in the opposite order starting from the same first point,
+// which reverses its winding
+func (h Hull) Reverse() Hull {
+	n := len(h.ps)
+	ps := make([]geometry.Point, n)
+	for i, p := range h.ps {
+		ps[(n-i)%n] = p
+	}
+	h.ps = ps
+	h.isCW = h.SignedArea() > 0
+	return h
+}
+
+// Orient returns the Hull wound clockwise if cw is true and counterclockwise otherwise, reversing it if needed
+func (h Hull) Orient(cw bool) Hull {
+	if h.isCW == cw || h.SignedArea() == 0 {
+		return h
+	}
+	return h.Reverse()
+}
+
+// Truncated returns whether the Hull touches the border of the image it was traced from,
+// in which case it may outline only the visible part of an object
+func (h Hull) Truncated() bool {
+	return h.truncated
 }
 
-func (h Hull) TopLeft() geometry.Point {
+// Map returns a new Hull with every point transformed by the function
+func (h Hull) Map(f func(geometry.Point) geometry.Point) Hull {
+	ps := make([]geometry.Point, len(h.ps))
+	for i, p := range h.ps {
+		ps[i] = f(p)
+	}
+	h.ps = ps
+	h.isCW = h.SignedArea() > 0
+	return h
+}
+
+// SignedArea returns the shoelace area enclosed by the Hull, positive if it winds clockwise
+func (h Hull) SignedArea() float64 {
+	a := 0.
+	n := len(h.ps)
+	for i, p := range h.ps {
+		a += p.Cross(h.ps[(i+1)%n])
+	}
+	return a / 2
+}
+
+// Area returns the area enclosed by the Hull
+func (h Hull) Area() float64 {
+	return math.Abs(h.SignedArea())
+}
+
+// Perimeter returns the length of the closed boundary of the Hull
+func (h Hull) Perimeter() float64 {
+	l := 0.
+	n := len(h.ps)
+	for i, p := range h.ps {
+		l += p.DistanceTo(h.ps[(i+1)%n])
+	}
+	return l
+}
+
+// Centroid returns the centroid of the region enclosed by the Hull
+//
+// It falls back to the mean of the boundary points if the Hull encloses no area
+func (h Hull) Centroid() geometry.Point {
+	var a, cx, cy float64
+	n := len(h.ps)
+	for i, p := range h.ps {
+		q := h.ps[(i+1)%n]
+		c := p.Cross(q)
+		a += c
+		cx += (p.X() + q.X()) * c
+		cy += (p.Y() + q.Y()) * c
+	}
+	if a == 0 {
+		xBar, yBar, _, _, _ := fit.Moments(h.ps)
+		return geometry.PointXY(xBar, yBar)
+	}
+	return geometry.PointXY(cx/(3*a), cy/(3*a))
+}
+
+// Contains returns whether the Point lies inside the Hull using the even-odd rule
+func (h Hull) Contains(p geometry.Point) bool {
+	in := false
+	n := len(h.ps)
+	for i, a := range h.ps {
+		c := h.ps[(i+1)%n]
+		if (a.Y() > p.Y()) != (c.Y() > p.Y()) && p.X() < a.X()+(p.Y()-a.Y())*(c.X()-a.X())/(c.Y()-a.Y()) {
+			in = !in
+		}
+	}
+	return in
+}
+
+func (h Hull) TopLeft() (geometry.Point, error) {
+	if len(h.ps) == 0 {
+		return geometry.Point{}, ErrEmptyHull
+	}
 	pi := h.ps[0]
 	for _, p := range h.ps {
 		if p.Y() < pi.Y() || p.Y() == pi.Y() && p.X() < pi.X() {
 			pi = p
 		}
 	}
-	return pi
+	return pi, nil
 }
 
 func (h Hull) Bounds() (geometry.Point, geometry.Point) {
@@ -72,7 +165,7 @@ func (h Hull) Bounds() (geometry.Point, geometry.Point) {
 func (h Hull) CenterPoint() (geometry.Point, float64) {
 	xBar, yBar, xyBar, x2Bar, y2Bar := fit.Moments(h.ps)
 	theta := math.Pi/4 + math.Atan2(x2Bar-xBar*xBar-y2Bar+yBar*yBar, 2*(xyBar-xBar*yBar))/2
-	return geometry.PointXY(xBar, yBar), theta
+	return h.Centroid(), theta
 }
 
 func (h Hull) Scale(f float64) Hull {
@@ -96,43 +189,16 @@ func (h Hull) RotateAbout(q geometry.Point, a float64) Hull {
 	return h
 }
 
-func (h Hull) Convex() Hull {
-	pi := h.TopLeft()
-
-	m := make(map[float64]int, len(h.ps))
-	for i, p := range h.ps {
-		a := pi.AngleTo(p)
-		ie, e := m[a]
-		if !e || pi.DistanceTo(h.ps[ie]) < pi.DistanceTo(p) {
-			m[a] = i
-		}
-	}
-
-	mr := make(map[int]float64, len(h.ps))
-	is := make([]int, len(m))
-	j := 0
-	for a, i := range m {
-		is[j] = i
-		mr[i] = a
-		j++
+// Convex returns the convex hull of the points of the Hull, which need not be in order, with the winding of the Hull
+func (h Hull) Convex() (Hull, error) {
+	if len(h.ps) == 0 {
+		return Hull{}, ErrEmptyHull
 	}
-
-	sort.Slice(is, func(i, j int) bool {
-		return mr[is[i]] < mr[is[j]]
-	})
-
-	qs := []geometry.Point{h.ps[is[0]], h.ps[is[1]]}
-	n := 2
-	for _, i := range is[2:] {
-		p := h.ps[i]
-		for n > 1 && qs[n-2].OrientationOf(qs[n-1], p) <= 0 {
-			qs = qs[:len(qs)-1]
-			n--
-		}
-		qs = append(qs, p)
-		n++
+	qs := geometry.ConvexHull(h.ps)
+	if len(qs) < 3 {
+		return Hull{}, ErrDegenerateHull
 	}
-	return HullPs(qs)
+	return h.derive(qs), nil
 }
 
 func (h Hull) Simplify(errorThreshold, lineThreshold float64) Hull {
@@ -154,166 +220,131 @@ func (h Hull) Simplify(errorThreshold, lineThreshold float64) Hull {
 	n := len(ls)
 	for i, l := range ls {
 		ln := ls[(i+1)%n]
-		p := l.IntersectionWith(ln)
+		p, err := l.IntersectionWith(ln)
+		// consecutive parallel lines continue the same side, so there is no corner between them
+		if err != nil {
+			continue
+		}
 		np := len(ps) - 1
 		if np >= 0 && ps[np].DistanceTo(p) < lineThreshold {
-			ps[np] = ls[(i-1)%n].IntersectionWith(ln)
-			ls[i] = ls[(i-1)%n]
+			lm := ls[(i-1+n)%n]
+			q, err := lm.IntersectionWith(ln)
+			if err != nil {
+				continue
+			}
+			ps[np] = q
+			ls[i] = lm
 		} else if l.AngleBetween(ln) > math.Pi/10 {
 			ps = append(ps, p)
 		}
 	}
-	return HullPs(ps)
+	return h.derive(ps)
 }
 
-func (h Hull) Draw(im *image.RGBA, cs ...color.RGBA) {
-	n := len(h.ps)
-	nc := len(cs)
-	if nc == 0 {
+// derive returns a new Hull through the points that keeps the winding and the truncation of the Hull
+func (h Hull) derive(ps []geometry.Point) Hull {
+	d := HullPs(ps).Orient(h.isCW)
+	d.truncated = h.truncated
+	return d
+}
+
+func (h Hull) Draw(im *image.RGBA, cs ...color.RGBA) error {
+	if len(cs) == 0 {
 		cs = append(cs, Black)
 	}
-	for i, p := range h.ps {
-		geometry.SegmentPQ(p, h.ps[(i+1)%n]).Draw(im, cs[i%nc])
-	}
-}
-
-func Hulls(im *image.Gray) (hs []Hull) {
-	links := make(map[image.Point]image.Point)
-
-	for y := 0; y < im.Rect.Dy()-1; y++ {
-		for x := 0; x < im.Rect.Dx()-1; x++ {
-			tl, tr, bl, br := im.GrayAt(x, y).Y, im.GrayAt(x+1, y).Y, im.GrayAt(x, y+1).Y, im.GrayAt(x+1, y+1).Y
-			switch {
-			case tl == w && tr == b && bl == w && br == w:
-				links[image.Point{X: x + 1, Y: y + 1}] = image.Point{X: x, Y: y}
-			case tl == b && tr == w && bl == w && br == w:
-				links[image.Point{X: x + 1, Y: y}] = image.Point{X: x, Y: y + 1}
-			case tl == w && tr == w && bl == b && br == w:
-				links[image.Point{X: x, Y: y}] = image.Point{X: x + 1, Y: y + 1}
-			case tl == w && tr == w && bl == w && br == b:
-				links[image.Point{X: x, Y: y + 1}] = image.Point{X: x + 1, Y: y}
-			case tl == b && tr == b && bl == w && br == w:
-				links[image.Point{X: x + 1, Y: y + 1}] = image.Point{X: x, Y: y + 1}
-			case tl == b && tr == w && bl == b && br == w:
-				links[image.Point{X: x + 1, Y: y}] = image.Point{X: x + 1, Y: y + 1}
-			case tl == w && tr == b && bl == w && br == b:
-				links[image.Point{X: x, Y: y + 1}] = image.Point{X: x, Y: y}
-			case tl == w && tr == w && bl == b && br == b:
-				links[image.Point{X: x, Y: y}] = image.Point{X: x + 1, Y: y}
-			}
+	// draw counterclockwise so that the colors cycle the same way around a Hull and its reverse
+	ps := h.Orient(false).ps
+	n, nc := len(ps), len(cs)
+	var err error
+	for i, p := range ps {
+		if e := geometry.SegmentPQ(p, ps[(i+1)%n]).Draw(im, cs[i%nc]); e != nil && err == nil {
+			err = e
 		}
 	}
+	return err
+}
 
-	var q, qi image.Point
-	var e bool
-
-	for len(links) > 0 {
-		var qs []image.Point
-		for qi = range links {
-			break
-		}
-
-		qs = append(qs, qi)
-		for {
-			q, e = links[qi]
-			if !e {
-				break
-			}
-			qs = append(qs, q)
-			delete(links, qi)
-			if q == qs[0] {
-				break
-			}
-			qi = q
-		}
-
-		ps := make([]geometry.Point, len(qs))
-		for i, q := range qs {
-			ps[i] = geometry.PointImage(q.X, q.Y)
-		}
-		h := HullPs(ps)
-		if h.Area() > thresholdArea {
-			hs = append(hs, h)
-		}
+// Hulls traces the outlines of the white regions of the binary image midway between their boundary pixels
+// and the black pixels around them, and returns those enclosing at least aMin pixels²
+//
+// White pixels touching only diagonally share an outline. The image is treated as surrounded by black
+// so that every outline closes, and outlines through pixels on the border are marked as truncated
+func Hulls(im *image.Gray, aMin float64, ws ...Workers) []Hull {
+	value := func(x, y int) float64 {
+		return float64(w - im.Pix[y*im.Stride+x])
 	}
-	return hs
+	g := newContourGrid(im.Rect.Dx(), im.Rect.Dy(), value, func(int, int) float64 { return float64(w) / 2 }, workersOf(ws))
+	g.binary = true
+	return gridHulls(g, aMin)
 }
 
-func SuperHulls(im *image.Gray, vm float64) []Hull {
-	type Link struct {
-		q image.Point
-		p geometry.Point
+// SuperHulls traces the sub-pixel iso-contours at level vm around the dark regions of the image
+// and returns those enclosing at least aMin pixels²
+//
+// If vm is AutoLevel, the level is selected from the histogram of the image using OtsuThreshold.
+// Regions touching the border are closed along the edge of the image and their Hulls are marked as truncated
+func SuperHulls(im *image.Gray, vm, aMin float64, ws ...Workers) []Hull {
+	if vm == AutoLevel {
+		vm = float64(OtsuThreshold(im)) + 0.5
 	}
-	links := make(map[image.Point]Link)
-
-	for y := 0; y < im.Rect.Dy()-1; y++ {
-		for x := 0; x < im.Rect.Dx()-1; x++ {
-			vTL, vTR, vBL, vBR := float64(im.GrayAt(x, y).Y), float64(im.GrayAt(x+1, y).Y), float64(im.GrayAt(x, y+1).Y), float64(im.GrayAt(x+1, y+1).Y)
-			mTL, mTR, mBL, mBR := thresholdPixelValue(vTL, vm), thresholdPixelValue(vTR, vm), thresholdPixelValue(vBL, vm), thresholdPixelValue(vBR, vm)
-
-			var p geometry.Point
-			if !(mTL == w && mTR == w && mBL == w && mBR == w) && !(mTL == b && mTR == b && mBL == b && mBR == b) {
-				gx, gy := (vTR-vBL+vBR-vTL)/2, (-vTR+vBL+vBR-vTL)/2
-				dv := vm - (vTL+vTR+vBL+vBR)/4
-				s := dv / (gx*gx + gy*gy)
-				p = geometry.PointXY(float64(x+1)+s*gx, float64(y+1)+s*gy)
-			}
+	return superHulls(im.Rect, grayValue(im), func(int, int) float64 { return vm }, aMin, workersOf(ws))
+}
 
-			switch { // todo: add 1px black padding to fix edge issues
-			case mTL == w && mTR == b && mBL == w && mBR == w:
-				links[image.Point{X: x + 1, Y: y + 1}] = Link{image.Point{X: x, Y: y}, p}
-			case mTL == b && mTR == w && mBL == w && mBR == w:
-				links[image.Point{X: x + 1, Y: y}] = Link{image.Point{X: x, Y: y + 1}, p}
-			case mTL == w && mTR == w && mBL == b && mBR == w:
-				links[image.Point{X: x, Y: y}] = Link{image.Point{X: x + 1, Y: y + 1}, p}
-			case mTL == w && mTR == w && mBL == w && mBR == b:
-				links[image.Point{X: x, Y: y + 1}] = Link{image.Point{X: x + 1, Y: y}, p}
-			case mTL == b && mTR == b && mBL == w && mBR == w:
-				links[image.Point{X: x + 1, Y: y + 1}] = Link{image.Point{X: x, Y: y + 1}, p}
-			case mTL == b && mTR == w && mBL == b && mBR == w:
-				links[image.Point{X: x + 1, Y: y}] = Link{image.Point{X: x + 1, Y: y + 1}, p}
-			case mTL == w && mTR == b && mBL == w && mBR == b:
-				links[image.Point{X: x, Y: y + 1}] = Link{image.Point{X: x, Y: y}, p}
-			case mTL == w && mTR == w && mBL == b && mBR == b:
-				links[image.Point{X: x, Y: y}] = Link{image.Point{X: x + 1, Y: y}, p}
-			}
-			// todo: maybe incorporate gradient information geometry.LineABC(gx, gy, -dv)
-		}
+// SuperHulls16 traces the sub-pixel iso-contours at level vm around the dark regions of the 16-bit image
+// and returns those enclosing at least aMin pixels²
+//
+// The level is on the 16-bit scale, and if it is AutoLevel it is selected using OtsuThreshold16
+func SuperHulls16(im *image.Gray16, vm, aMin float64, ws ...Workers) []Hull {
+	if vm == AutoLevel {
+		vm = float64(OtsuThreshold16(im)) + 0.5
 	}
+	return superHulls(im.Rect, gray16Value(im), func(int, int) float64 { return vm }, aMin, workersOf(ws))
+}
 
-	var hs []Hull
-	var q0, q image.Point
-	var l Link
-	var e bool
-
-	for len(links) > 0 {
-		var ps []geometry.Point
-		for q0 = range links {
-			break
-		}
+// SurfaceSuperHulls traces the sub-pixel contours around the dark regions of the image where each pixel
+// is compared against its own threshold on the Surface and returns those enclosing at least aMin pixels²
+//
+// It returns ErrBoundsMismatch if the Surface was not made for the bounds of the image
+func SurfaceSuperHulls(im *image.Gray, s Surface, aMin float64, ws ...Workers) ([]Hull, error) {
+	if s.rect != im.Rect {
+		return nil, ErrBoundsMismatch
+	}
+	level := func(x, y int) float64 {
+		return s.At(x+im.Rect.Min.X, y+im.Rect.Min.Y)
+	}
+	return superHulls(im.Rect, grayValue(im), level, aMin, workersOf(ws)), nil
+}
 
-		q = q0
-		for {
-			l, e = links[q]
-			if !e {
-				break
-			}
-			ps = append(ps, l.p)
-			delete(links, q)
-			if l.q == q0 {
-				break
-			}
-			q = l.q
-		}
+func superHulls(rect image.Rectangle, value, level func(x, y int) float64, aMin float64, ws Workers) []Hull {
+	return gridHulls(newContourGrid(rect.Dx(), rect.Dy(), value, level, ws), aMin)
+}
 
-		h := HullPs(ps)
-		if h.Area() > thresholdArea {
+// gridHulls returns the Hulls of the contours of the grid enclosing at least aMin pixels²
+func gridHulls(g contourGrid, aMin float64) []Hull {
+	var hs []Hull
+	for _, c := range g.contours() {
+		h := HullPs(c.ps)
+		h.truncated = c.truncated
+		if h.Area() >= aMin {
 			hs = append(hs, h)
 		}
 	}
 	return hs
 }
 
+func grayValue(im *image.Gray) func(x, y int) float64 {
+	return func(x, y int) float64 {
+		return float64(im.Pix[y*im.Stride+x])
+	}
+}
+
+func gray16Value(im *image.Gray16) func(x, y int) float64 {
+	return func(x, y int) float64 {
+		i := y*im.Stride + 2*x
+		return float64(uint16(im.Pix[i])<<8 | uint16(im.Pix[i+1]))
+	}
+}
+
 func thresholdPixelValue(v, vm float64) uint8 {
 	if v <= vm {
 		return w
@@ -342,30 +373,3 @@ func getUnitSegment(l geometry.Line) (bool, geometry.Segment) {
 	}
 	return true, geometry.SegmentPQ(ps[0], ps[1])
 }
-
-/* todo: implement a way to compare two hulls
-https://en.wikipedia.org/wiki/Fr%C3%A9chet_distance
-https://en.wikipedia.org/wiki/Dynamic_time_warping
-
-int DTWDistance(s: array [1..n], t: array [1..m], w: int) {
-    DTW := array [0..n, 0..m]
-
-    w := max(w, abs(n-m)) // adapt window size (*)
-
-    for i := 0 q n
-        for j:= 0 q m
-            DTW[i, j] := infinity
-    DTW[0, 0] := 0
-    for i := 1 q n
-        for j := max(1, i-w) q min(m, i+w)
-            DTW[i, j] := 0
-
-    for i := 1 q n
-        for j := max(1, i-w) q min(m, i+w)
-            cost := D(s[i], t[j])
-            DTW[i, j] := cost + minimum(DTW[i-1, j  ],    // insertion
-                                        DTW[i  , j-1],    // deletion
-                                        DTW[i-1, j-1])    // match
-    return DTW[n, m]
-}
-*/
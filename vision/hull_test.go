@@ -0,0 +1,322 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"screwSort/geometry"
+	"testing"
+)
+
+// grayRows returns the binary image with a white pixel for every '#' of the rows and a black one otherwise
+func grayRows(rows ...string) *image.Gray {
+	im := image.NewGray(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, c := range row {
+			if c == '#' {
+				im.Pix[y*im.Stride+x] = w
+			} else {
+				im.Pix[y*im.Stride+x] = b
+			}
+		}
+	}
+	return im
+}
+
+// hullXY returns the Hull through the points given as consecutive x and y coordinates
+func hullXY(vs ...float64) Hull {
+	ps := make([]geometry.Point, len(vs)/2)
+	for i := range ps {
+		ps[i] = geometry.PointXY(vs[2*i], vs[2*i+1])
+	}
+	return HullPs(ps)
+}
+
+func TestHullShoelace(t *testing.T) {
+	tests := []struct {
+		name       string
+		h          Hull
+		signedArea float64
+		perimeter  float64
+		centroid   geometry.Point
+	}{
+		// y points down, so a Hull going right then down winds clockwise on screen and has positive signed area
+		{"clockwise square", hullXY(0, 0, 4, 0, 4, 4, 0, 4), 16, 16, geometry.PointXY(2, 2)},
+		{"counterclockwise square", hullXY(0, 0, 0, 4, 4, 4, 4, 0), -16, 16, geometry.PointXY(2, 2)},
+		{"right triangle", hullXY(0, 0, 6, 0, 0, 3), 9, 9 + math.Sqrt(45), geometry.PointXY(2, 1)},
+		{"translated rectangle", hullXY(10, 20, 13, 20, 13, 22, 10, 22), 6, 10, geometry.PointXY(11.5, 21)},
+		// the centroid of the region is not the mean of the vertices
+		{"L shape", hullXY(0, 0, 2, 0, 2, 2, 6, 2, 6, 4, 0, 4), 16, 20, geometry.PointXY(2.5, 2.5)},
+		{"collinear", hullXY(0, 0, 1, 1, 2, 2), 0, 2 * math.Sqrt(8), geometry.PointXY(1, 1)},
+		{"empty", HullPs(nil), 0, 0, geometry.Point{}},
+	}
+	const eps = 1e-12
+	for _, tt := range tests {
+		if got := tt.h.SignedArea(); math.Abs(got-tt.signedArea) > eps {
+			t.Errorf("%s: SignedArea() = %v, want %v", tt.name, got, tt.signedArea)
+		}
+		if got := tt.h.Area(); math.Abs(got-math.Abs(tt.signedArea)) > eps {
+			t.Errorf("%s: Area() = %v, want %v", tt.name, got, math.Abs(tt.signedArea))
+		}
+		if got := tt.h.Perimeter(); math.Abs(got-tt.perimeter) > eps {
+			t.Errorf("%s: Perimeter() = %v, want %v", tt.name, got, tt.perimeter)
+		}
+		if len(tt.h.Ps()) == 0 {
+			continue
+		}
+		if got := tt.h.Centroid(); got.DistanceTo(tt.centroid) > eps {
+			t.Errorf("%s: Centroid() = %v, want %v", tt.name, got, tt.centroid)
+		}
+	}
+}
+
+func TestHullsClosed(t *testing.T) {
+	tests := []struct {
+		name  string
+		im    *image.Gray
+		outer int
+		holes int
+	}{
+		{"block with spur", grayRows(
+			"............",
+			"............",
+			"..#######...",
+			"..#######...",
+			"..#######...",
+			"....#.......",
+			"....#.......",
+			"............",
+		), 1, 0},
+		{"ring", grayRows(
+			".......",
+			".#####.",
+			".#####.",
+			".##.##.",
+			".#####.",
+			".#####.",
+			".......",
+		), 1, 1},
+		// white pixels touching diagonally belong to the same outline like 8-connected Components
+		{"diagonal pixels", grayRows(
+			"......",
+			".#....",
+			"..#...",
+			"...#..",
+			"......",
+		), 1, 0},
+		{"touching the border", grayRows(
+			"###...",
+			"###...",
+			"......",
+		), 1, 0},
+	}
+	for _, tt := range tests {
+		var outer, holes int
+		for _, h := range Hulls(tt.im, 0) {
+			if len(h.Ps()) < 3 || h.Area() == 0 {
+				t.Errorf("%s: open or degenerate Hull with %d points and area %v", tt.name, len(h.Ps()), h.Area())
+			}
+			if h.IsCW() {
+				holes++
+			} else {
+				outer++
+			}
+		}
+		if outer != tt.outer || holes != tt.holes {
+			t.Errorf("%s: got %d outer and %d hole Hulls, want %d and %d", tt.name, outer, holes, tt.outer, tt.holes)
+		}
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	tests := []struct {
+		name string
+		im   *image.Gray
+		want bool
+	}{
+		{"inside", grayRows(
+			"......",
+			".###..",
+			".###..",
+			"......",
+		), false},
+		{"left", grayRows(
+			"......",
+			"###...",
+			"###...",
+			"......",
+		), true},
+		{"bottom right", grayRows(
+			"......",
+			"......",
+			"...###",
+			"...###",
+		), true},
+		{"top", grayRows(
+			"..##..",
+			"..##..",
+			"......",
+			"......",
+		), true},
+		{"right", grayRows(
+			"......",
+			"....##",
+			"....##",
+			"......",
+		), true},
+		{"whole image", grayRows(
+			"######",
+			"######",
+		), true},
+		{"one pixel from the border", grayRows(
+			".......",
+			".#####.",
+			".#####.",
+			".......",
+		), false},
+	}
+	for _, tt := range tests {
+		ls, cs := Label(tt.im, Connectivity8)
+		extractors := map[string][]Hull{
+			"Hulls":          Hulls(tt.im, 0),
+			"SuperHulls":     SuperHulls(invert(tt.im), 127.5, 0),
+			"ComponentHulls": ComponentHulls(ls, cs, 0),
+		}
+		for name, hs := range extractors {
+			if len(hs) != 1 {
+				t.Errorf("%s %s: got %d Hulls, want 1", name, tt.name, len(hs))
+				continue
+			}
+			if hs[0].Truncated() != tt.want {
+				t.Errorf("%s %s: Truncated() = %v, want %v", name, tt.name, hs[0].Truncated(), tt.want)
+			}
+		}
+	}
+}
+
+func TestTruncatedSubImage(t *testing.T) {
+	im := grayRows(
+		"........",
+		"........",
+		"..###...",
+		"..###...",
+		"........",
+	)
+	tests := []struct {
+		name string
+		r    image.Rectangle
+		want bool
+	}{
+		{"around the block", image.Rect(1, 1, 6, 5), false},
+		{"cutting the block", image.Rect(3, 0, 8, 5), true},
+		{"block at the corner", image.Rect(2, 2, 7, 5), true},
+	}
+	for _, tt := range tests {
+		sub := im.SubImage(tt.r).(*image.Gray)
+		hs := Hulls(sub, 0)
+		if len(hs) != 1 {
+			t.Errorf("%s: got %d Hulls, want 1", tt.name, len(hs))
+			continue
+		}
+		if hs[0].Truncated() != tt.want {
+			t.Errorf("%s: Truncated() = %v, want %v", tt.name, hs[0].Truncated(), tt.want)
+		}
+		if rs := Regions(hs); len(rs) != 1 || rs[0].Truncated() != tt.want {
+			t.Errorf("%s: Region is not truncated like its outer Hull", tt.name)
+		}
+	}
+}
+
+func TestTruncatedClosesAlongBorder(t *testing.T) {
+	// a dark block cut by the left border is closed along the edge of the image
+	im := invert(grayRows(
+		"......",
+		"###...",
+		"###...",
+		"###...",
+		"......",
+	))
+	hs := SuperHulls(im, 127.5, 0)
+	if len(hs) != 1 || !hs[0].Truncated() {
+		t.Fatalf("got %d Hulls, want 1 truncated", len(hs))
+	}
+	pMin, pMax := hs[0].Bounds()
+	if pMin.X() < 0 || pMin.Y() < 0 || pMax.X() > 6 || pMax.Y() > 5 {
+		t.Errorf("Hull bounds %v, %v leave the image", pMin, pMax)
+	}
+	// the outline of the 3×3 block runs midway to the pixels around it, or to the virtual ones beyond the edge,
+	// and cuts each corner by a quarter pixel²
+	if a := hs[0].Area(); math.Abs(a-8) > 1e-9 {
+		t.Errorf("Area() = %v, want 8", a)
+	}
+}
+
+func TestHullOrientation(t *testing.T) {
+	cw := hullXY(0, 0, 4, 0, 4, 3, 0, 3)
+	ccw := hullXY(0, 0, 0, 3, 4, 3, 4, 0)
+	tests := []struct {
+		name string
+		h    Hull
+		isCW bool
+	}{
+		{"clockwise", cw, true},
+		{"counterclockwise", ccw, false},
+		{"reversed clockwise", cw.Reverse(), false},
+		{"reversed counterclockwise", ccw.Reverse(), true},
+		{"oriented clockwise", ccw.Orient(true), true},
+		{"oriented counterclockwise", cw.Orient(false), false},
+		{"already oriented", cw.Orient(true), true},
+		{"collinear", hullXY(0, 0, 1, 0, 2, 0), false},
+		{"collinear oriented clockwise", hullXY(0, 0, 1, 0, 2, 0).Orient(true), false},
+		{"mirrored", cw.Map(func(p geometry.Point) geometry.Point { return geometry.PointXY(-p.X(), p.Y()) }), false},
+		{"resampled", cw.Resample(20), true},
+		{"convex", hullOf(cw.Convex()), true},
+		{"convex of counterclockwise", hullOf(ccw.Convex()), false},
+	}
+	for _, tt := range tests {
+		if tt.h.IsCW() != tt.isCW {
+			t.Errorf("%s: IsCW() = %v, want %v", tt.name, tt.h.IsCW(), tt.isCW)
+		}
+		if a := tt.h.SignedArea(); tt.isCW && a <= 0 || !tt.isCW && a > 0 {
+			t.Errorf("%s: SignedArea() = %v does not match IsCW() = %v", tt.name, a, tt.isCW)
+		}
+	}
+
+	r := cw.Reverse()
+	if r.Ps()[0] != cw.Ps()[0] || r.Ps()[1] != cw.Ps()[3] {
+		t.Errorf("Reverse() = %v, want the points of %v in the opposite order from the same first point", r.Ps(), cw.Ps())
+	}
+}
+
+func TestTracedOrientation(t *testing.T) {
+	ring := grayRows(
+		".......",
+		".#####.",
+		".#####.",
+		".##.##.",
+		".#####.",
+		".#####.",
+		".......",
+	)
+	for name, hs := range map[string][]Hull{
+		"Hulls":      Hulls(ring, 0),
+		"SuperHulls": SuperHulls(invert(ring), 127.5, 0),
+	} {
+		if len(hs) != 2 {
+			t.Errorf("%s: got %d Hulls, want 2", name, len(hs))
+			continue
+		}
+		// the outer boundary is larger than the hole
+		outer, hole := hs[0], hs[1]
+		if outer.Area() < hole.Area() {
+			outer, hole = hole, outer
+		}
+		if outer.IsCW() || !hole.IsCW() {
+			t.Errorf("%s: outer IsCW() = %v and hole IsCW() = %v, want false and true", name, outer.IsCW(), hole.IsCW())
+		}
+	}
+}
+
+// hullOf returns the Hull of a result ignoring its error
+func hullOf(h Hull, _ error) Hull {
+	return h
+}
@@ -1,12 +1,25 @@
 package vision
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
+	"image/jpeg"
 	"image/png"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/tiff"
 )
 
+// JpegQuality is the quality used by Save when encoding JPEG files
+const JpegQuality = 95
+
+// ErrUnknownFormat is returned when the image format cannot be determined from the file extension
+var ErrUnknownFormat = errors.New("vision: unknown image format")
+
 var (
 	Black   = color.RGBA{A: 255}
 	White   = color.RGBA{R: 255, G: 255, B: 255, A: 255}
@@ -24,10 +37,8 @@ func BlackRgba(dx, dy int) *image.RGBA {
 			Min: image.Point{},
 			Max: image.Point{X: dx, Y: dy},
 		})
-	for y := out.Rect.Min.Y; y < out.Rect.Max.Y; y++ {
-		for x := out.Rect.Min.X; x < out.Rect.Max.X; x++ {
-			out.Set(x, y, Black)
-		}
+	for i := 3; i < len(out.Pix); i += 4 {
+		out.Pix[i] = Black.A
 	}
 	return out
 }
@@ -41,47 +52,222 @@ func BlackGray(dx, dy int) *image.Gray {
 	return out
 }
 
-func SavePng(im image.Image, n string) {
-	f, _ := os.Create(n)
-	_ = png.Encode(f, im)
-	_ = f.Close()
+// SavePng encodes the image as a PNG file
+func SavePng(im image.Image, fn string) error {
+	if im == nil {
+		return ErrNilImage
+	}
+	f, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, im); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("vision: failed to encode %s: %w", fn, err)
+	}
+	return f.Close()
 }
 
-func OpenPng(fn string) image.Image {
-	f, _ := os.Open(fn)
-	im, _ := png.Decode(f)
-	_ = f.Close()
-	return im
+// OpenPng decodes the PNG file
+func OpenPng(fn string) (image.Image, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	im, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("vision: failed to decode %s: %w", fn, err)
+	}
+	return im, nil
+}
+
+// Open decodes the image file of any registered format, detected from its contents:
+// PNG, JPEG, TIFF, and binary or plain PGM/PPM including 16-bit samples
+func Open(fn string) (image.Image, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	im, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("vision: failed to decode %s: %w", fn, err)
+	}
+	return im, nil
+}
+
+// Save encodes the image in the format given by the extension of the file name:
+// .png, .jpg/.jpeg, .tif/.tiff, or .pgm/.ppm/.pnm
+//
+// PNG, TIFF, and PGM keep 16-bit samples of *image.Gray16 images
+func Save(im image.Image, fn string) error {
+	if im == nil {
+		return ErrNilImage
+	}
+	var encode func(f *os.File) error
+	switch strings.ToLower(filepath.Ext(fn)) {
+	case ".png":
+		encode = func(f *os.File) error { return png.Encode(f, im) }
+	case ".jpg", ".jpeg":
+		encode = func(f *os.File) error { return jpeg.Encode(f, im, &jpeg.Options{Quality: JpegQuality}) }
+	case ".tif", ".tiff":
+		encode = func(f *os.File) error { return tiff.Encode(f, im, &tiff.Options{Compression: tiff.Deflate}) }
+	case ".pgm", ".ppm", ".pnm":
+		encode = func(f *os.File) error { return EncodePnm(f, im) }
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, fn)
+	}
+
+	f, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+	if err = encode(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("vision: failed to encode %s: %w", fn, err)
+	}
+	return f.Close()
 }
 
+// ToGray converts the image to gray with the luma weights of color.GrayModel,
+// reading the pixel buffers of *image.Gray, *image.RGBA, *image.NRGBA, and *image.YCbCr directly
 func ToGray(im image.Image) *image.Gray {
-	out := image.NewGray(im.Bounds())
-	for y := 0; y < im.Bounds().Dy(); y++ {
-		for x := 0; x < im.Bounds().Dx(); x++ {
-			out.Set(x, y, im.At(x, y))
+	r := im.Bounds()
+	out := image.NewGray(r)
+	dx := r.Dx()
+	switch src := im.(type) {
+	case *image.Gray:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			copy(out.Pix[out.PixOffset(r.Min.X, y):], src.Pix[src.PixOffset(r.Min.X, y):src.PixOffset(r.Max.X, y)])
+		}
+	case *image.RGBA:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row, outRow := src.Pix[src.PixOffset(r.Min.X, y):], out.Pix[out.PixOffset(r.Min.X, y):]
+			for x := 0; x < dx; x++ {
+				c := row[4*x : 4*x+4 : 4*x+4]
+				outRow[x] = luma(uint32(c[0])*0x101, uint32(c[1])*0x101, uint32(c[2])*0x101)
+			}
+		}
+	case *image.NRGBA:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row, outRow := src.Pix[src.PixOffset(r.Min.X, y):], out.Pix[out.PixOffset(r.Min.X, y):]
+			for x := 0; x < dx; x++ {
+				c := row[4*x : 4*x+4 : 4*x+4]
+				cr, cg, cb, _ := color.NRGBA{R: c[0], G: c[1], B: c[2], A: c[3]}.RGBA()
+				outRow[x] = luma(cr, cg, cb)
+			}
+		}
+	case *image.YCbCr:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			outRow := out.Pix[out.PixOffset(r.Min.X, y):]
+			for x := 0; x < dx; x++ {
+				yi, ci := src.YOffset(r.Min.X+x, y), src.COffset(r.Min.X+x, y)
+				cr, cg, cb, _ := color.YCbCr{Y: src.Y[yi], Cb: src.Cb[ci], Cr: src.Cr[ci]}.RGBA()
+				outRow[x] = luma(cr, cg, cb)
+			}
+		}
+	default:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				out.Set(x, y, im.At(x, y))
+			}
+		}
+	}
+	return out
+}
+
+// luma returns the 8-bit gray value of the 16-bit color channels as computed by color.GrayModel
+func luma(r, g, b uint32) uint8 {
+	return uint8((19595*r + 38470*g + 7471*b + 1<<15) >> 24)
+}
+
+// ToGray16 converts the image to 16-bit gray, keeping the full precision of 16-bit sources
+func ToGray16(im image.Image) *image.Gray16 {
+	r := im.Bounds()
+	out := image.NewGray16(r)
+	switch src := im.(type) {
+	case *image.Gray16:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			copy(out.Pix[out.PixOffset(r.Min.X, y):], src.Pix[src.PixOffset(r.Min.X, y):src.PixOffset(r.Max.X, y)])
+		}
+	case *image.Gray:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row, outRow := src.Pix[src.PixOffset(r.Min.X, y):], out.Pix[out.PixOffset(r.Min.X, y):]
+			for x := 0; x < r.Dx(); x++ {
+				outRow[2*x], outRow[2*x+1] = row[x], row[x]
+			}
+		}
+	default:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				out.Set(x, y, im.At(x, y))
+			}
 		}
 	}
 	return out
 }
 
+// ToRgba converts the image to premultiplied RGBA,
+// reading the pixel buffers of *image.Gray, *image.RGBA, *image.NRGBA, and *image.YCbCr directly
 func ToRgba(im image.Image) *image.RGBA {
-	out := image.NewRGBA(im.Bounds())
-	for y := 0; y < im.Bounds().Dy(); y++ {
-		for x := 0; x < im.Bounds().Dx(); x++ {
-			out.Set(x, y, im.At(x, y))
+	r := im.Bounds()
+	out := image.NewRGBA(r)
+	dx := r.Dx()
+	switch src := im.(type) {
+	case *image.RGBA:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			copy(out.Pix[out.PixOffset(r.Min.X, y):], src.Pix[src.PixOffset(r.Min.X, y):src.PixOffset(r.Max.X, y)])
+		}
+	case *image.Gray:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row, outRow := src.Pix[src.PixOffset(r.Min.X, y):], out.Pix[out.PixOffset(r.Min.X, y):]
+			for x := 0; x < dx; x++ {
+				v := row[x]
+				setRgba(outRow[4*x:], uint32(v)*0x101, uint32(v)*0x101, uint32(v)*0x101, 0xffff)
+			}
+		}
+	case *image.NRGBA:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row, outRow := src.Pix[src.PixOffset(r.Min.X, y):], out.Pix[out.PixOffset(r.Min.X, y):]
+			for x := 0; x < dx; x++ {
+				c := row[4*x : 4*x+4 : 4*x+4]
+				cr, cg, cb, ca := color.NRGBA{R: c[0], G: c[1], B: c[2], A: c[3]}.RGBA()
+				setRgba(outRow[4*x:], cr, cg, cb, ca)
+			}
+		}
+	case *image.YCbCr:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			outRow := out.Pix[out.PixOffset(r.Min.X, y):]
+			for x := 0; x < dx; x++ {
+				yi, ci := src.YOffset(r.Min.X+x, y), src.COffset(r.Min.X+x, y)
+				cr, cg, cb, ca := color.YCbCr{Y: src.Y[yi], Cb: src.Cb[ci], Cr: src.Cr[ci]}.RGBA()
+				setRgba(outRow[4*x:], cr, cg, cb, ca)
+			}
+		}
+	default:
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				out.Set(x, y, im.At(x, y))
+			}
 		}
 	}
 	return out
 }
 
+// setRgba stores the 16-bit color channels in the pixel as color.RGBAModel does
+func setRgba(pix []uint8, r, g, b, a uint32) {
+	pix[0], pix[1], pix[2], pix[3] = uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8)
+}
+
 func ApplyAlpha(im *image.RGBA, a float64) *image.RGBA {
-	for y := 0; y < im.Bounds().Dy(); y++ {
-		for x := 0; x < im.Bounds().Dx(); x++ {
-			c := im.RGBAAt(x, y)
-			c.R = uint8(float64(c.R) * a)
-			c.G = uint8(float64(c.G) * a)
-			c.B = uint8(float64(c.B) * a)
-			im.Set(x, y, c)
+	dx := im.Rect.Dx()
+	for y := 0; y < im.Rect.Dy(); y++ {
+		row := im.Pix[y*im.Stride : y*im.Stride+4*dx]
+		for i := 0; i < len(row); i += 4 {
+			row[i] = uint8(float64(row[i]) * a)
+			row[i+1] = uint8(float64(row[i+1]) * a)
+			row[i+2] = uint8(float64(row[i+2]) * a)
 		}
 	}
 	return im
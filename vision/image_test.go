@@ -0,0 +1,121 @@
+package vision
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// generic hides the concrete type of an image so that conversions fall back to At and Set
+type generic struct {
+	image.Image
+}
+
+// testImages returns images of every type with a fast path holding the same smooth pattern with noise
+func testImages(dx, dy int) map[string]image.Image {
+	r := image.Rect(0, 0, dx, dy)
+	gray, rgba, nrgba := image.NewGray(r), image.NewRGBA(r), image.NewNRGBA(r)
+	ycbcr := image.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			v := uint8(x*7 + y*3 + (x*y)%13)
+			c := color.NRGBA{R: v, G: uint8(x), B: uint8(y), A: uint8(128 + x%128)}
+			gray.SetGray(x, y, color.Gray{Y: v})
+			rgba.Set(x, y, c)
+			nrgba.SetNRGBA(x, y, c)
+			ycbcr.Y[ycbcr.YOffset(x, y)] = v
+			ci := ycbcr.COffset(x, y)
+			ycbcr.Cb[ci], ycbcr.Cr[ci] = uint8(x), uint8(y)
+		}
+	}
+	return map[string]image.Image{"Gray": gray, "RGBA": rgba, "NRGBA": nrgba, "YCbCr": ycbcr}
+}
+
+func TestToGrayMatchesGeneric(t *testing.T) {
+	for name, im := range testImages(37, 23) {
+		if got, want := ToGray(im), ToGray(generic{im}); !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("ToGray(%s) differs from the generic conversion", name)
+		}
+	}
+}
+
+func TestToRgbaMatchesGeneric(t *testing.T) {
+	for name, im := range testImages(37, 23) {
+		if got, want := ToRgba(im), ToRgba(generic{im}); !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("ToRgba(%s) differs from the generic conversion", name)
+		}
+	}
+}
+
+func TestSaveNilImage(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "nil.png")
+	if err := Save(nil, fn); err != ErrNilImage {
+		t.Errorf("Save: got %v, want ErrNilImage", err)
+	}
+	if err := SavePng(nil, fn); err != ErrNilImage {
+		t.Errorf("SavePng: got %v, want ErrNilImage", err)
+	}
+	if _, err := os.Stat(fn); !os.IsNotExist(err) {
+		t.Errorf("a file was created for a nil image: %v", err)
+	}
+}
+
+func BenchmarkToGray(b *testing.B) {
+	for name, im := range testImages(1024, 768) {
+		for _, c := range []struct {
+			path string
+			im   image.Image
+		}{{"Pix", im}, {"Generic", generic{im}}} {
+			b.Run(name+"/"+c.path, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					ToGray(c.im)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkToRgba(b *testing.B) {
+	for name, im := range testImages(1024, 768) {
+		for _, c := range []struct {
+			path string
+			im   image.Image
+		}{{"Pix", im}, {"Generic", generic{im}}} {
+			b.Run(name+"/"+c.path, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					ToRgba(c.im)
+				}
+			})
+		}
+	}
+}
+
+// applyAlphaGeneric scales the color channels of every pixel through At and Set
+func applyAlphaGeneric(im *image.RGBA, a float64) *image.RGBA {
+	for y := im.Rect.Min.Y; y < im.Rect.Max.Y; y++ {
+		for x := im.Rect.Min.X; x < im.Rect.Max.X; x++ {
+			c := im.RGBAAt(x, y)
+			im.SetRGBA(x, y, color.RGBA{R: uint8(float64(c.R) * a), G: uint8(float64(c.G) * a), B: uint8(float64(c.B) * a), A: c.A})
+		}
+	}
+	return im
+}
+
+func BenchmarkApplyAlpha(b *testing.B) {
+	for name, im := range testImages(1024, 768) {
+		rgba := ToRgba(im)
+		b.Run(name+"/Pix", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ApplyAlpha(rgba, 0.999)
+			}
+		})
+		b.Run(name+"/Generic", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				applyAlphaGeneric(rgba, 0.999)
+			}
+		})
+	}
+}
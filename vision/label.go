@@ -0,0 +1,227 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"screwSort/geometry"
+)
+
+// Connectivity selects which neighbors of a pixel belong to the same component
+type Connectivity uint8
+
+const (
+	Connectivity4 Connectivity = 4
+	Connectivity8 Connectivity = 8
+)
+
+// Labels describes the component label of every pixel of an image, with 0 marking the background
+type Labels struct {
+	rect image.Rectangle
+	ls   []int
+}
+
+// Bounds returns the bounds of the Labels
+func (l Labels) Bounds() image.Rectangle {
+	return l.rect
+}
+
+// At returns the label of the pixel or 0 if it is outside the bounds
+func (l Labels) At(x, y int) int {
+	if !(image.Point{X: x, Y: y}).In(l.rect) {
+		return 0
+	}
+	return l.ls[(y-l.rect.Min.Y)*l.rect.Dx()+x-l.rect.Min.X]
+}
+
+// Mask returns the binary image that is white where the pixel has one of the labels
+func (l Labels) Mask(labels ...int) *image.Gray {
+	keep := make(map[int]bool, len(labels))
+	for _, k := range labels {
+		keep[k] = true
+	}
+	out := image.NewGray(l.rect)
+	for i, k := range l.ls {
+		if k != 0 && keep[k] {
+			out.Pix[(i/l.rect.Dx())*out.Stride+i%l.rect.Dx()] = w
+		}
+	}
+	return out
+}
+
+// Component describes the statistics of a connected region of white pixels
+type Component struct {
+	label            int
+	area             int
+	bounds           image.Rectangle
+	centroid         geometry.Point
+	mu20, mu02, mu11 float64
+	ps               []image.Point
+}
+
+// Label returns the label of the Component in the Labels
+func (c Component) Label() int {
+	return c.label
+}
+
+// Area returns the number of pixels in the Component
+func (c Component) Area() int {
+	return c.area
+}
+
+// Bounds returns the smallest rectangle of pixels containing the Component
+func (c Component) Bounds() image.Rectangle {
+	return c.bounds
+}
+
+// Centroid returns the mean of the pixel centers of the Component
+func (c Component) Centroid() geometry.Point {
+	return c.centroid
+}
+
+// Moments returns the second central moments μ20, μ02, and μ11 of the Component normalized by its area
+func (c Component) Moments() (float64, float64, float64) {
+	return c.mu20, c.mu02, c.mu11
+}
+
+// Orientation returns the angle of the major axis of the Component clockwise from +x
+func (c Component) Orientation() float64 {
+	return math.Atan2(2*c.mu11, c.mu20-c.mu02) / 2
+}
+
+// Eccentricity returns the eccentricity of the ellipse with the same second moments as the Component
+func (c Component) Eccentricity() float64 {
+	d := math.Hypot(c.mu20-c.mu02, 2*c.mu11)
+	l1, l2 := (c.mu20+c.mu02+d)/2, (c.mu20+c.mu02-d)/2
+	if l1 <= 0 {
+		return 0
+	}
+	return math.Sqrt(1 - l2/l1)
+}
+
+// Points returns the pixels of the Component in raster order
+func (c Component) Points() []image.Point {
+	return c.ps
+}
+
+// Label finds the connected components of the white pixels of the binary image
+// using a two-pass scan with union-find and returns the Labels and the Components ordered by label
+func Label(im *image.Gray, conn Connectivity) (Labels, []Component) {
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	ls := make([]int, dx*dy)
+	parents := []int{0}
+
+	find := func(i int) int {
+		for parents[i] != i {
+			parents[i] = parents[parents[i]]
+			i = parents[i]
+		}
+		return i
+	}
+	union := func(i, j int) int {
+		i, j = find(i), find(j)
+		if i < j {
+			parents[j] = i
+			return i
+		}
+		parents[i] = j
+		return j
+	}
+
+	neighbors := [][2]int{{-1, 0}, {0, -1}}
+	if conn == Connectivity8 {
+		neighbors = append(neighbors, [2]int{-1, -1}, [2]int{1, -1})
+	}
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			if im.Pix[y*im.Stride+x] == b {
+				continue
+			}
+			l := 0
+			for _, n := range neighbors {
+				nx, ny := x+n[0], y+n[1]
+				if nx < 0 || ny < 0 || nx >= dx {
+					continue
+				}
+				if k := ls[ny*dx+nx]; k != 0 {
+					if l == 0 {
+						l = find(k)
+					} else {
+						l = union(l, k)
+					}
+				}
+			}
+			if l == 0 {
+				l = len(parents)
+				parents = append(parents, l)
+			}
+			ls[y*dx+x] = l
+		}
+	}
+
+	// relabel the roots consecutively in raster order of their first pixel
+	compact := make([]int, len(parents))
+	n := 0
+	for i, l := range ls {
+		if l == 0 {
+			continue
+		}
+		r := find(l)
+		if compact[r] == 0 {
+			n++
+			compact[r] = n
+		}
+		ls[i] = compact[r]
+	}
+	return Labels{im.Rect, ls}, components(im.Rect, ls, n)
+}
+
+// components computes the statistics of the n labeled Components
+func components(rect image.Rectangle, ls []int, n int) []Component {
+	dx := rect.Dx()
+	cs := make([]Component, n)
+	sx, sy, sxx, syy, sxy := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	for i, l := range ls {
+		if l == 0 {
+			continue
+		}
+		k := l - 1
+		p := image.Point{X: rect.Min.X + i%dx, Y: rect.Min.Y + i/dx}
+		c := &cs[k]
+		if c.area == 0 {
+			c.label = l
+			c.bounds = image.Rectangle{Min: p, Max: p.Add(image.Point{X: 1, Y: 1})}
+		} else {
+			c.bounds = c.bounds.Union(image.Rectangle{Min: p, Max: p.Add(image.Point{X: 1, Y: 1})})
+		}
+		c.area++
+		c.ps = append(c.ps, p)
+		q := geometry.PointImage(p.X, p.Y)
+		sx[k] += q.X()
+		sy[k] += q.Y()
+		sxx[k] += q.X() * q.X()
+		syy[k] += q.Y() * q.Y()
+		sxy[k] += q.X() * q.Y()
+	}
+	for k := range cs {
+		c := &cs[k]
+		a := float64(c.area)
+		xBar, yBar := sx[k]/a, sy[k]/a
+		c.centroid = geometry.PointXY(xBar, yBar)
+		c.mu20 = sxx[k]/a - xBar*xBar
+		c.mu02 = syy[k]/a - yBar*yBar
+		c.mu11 = sxy[k]/a - xBar*yBar
+	}
+	return cs
+}
+
+// RemoveSmallComponents returns a copy of the binary image without the components of fewer than aMin pixels
+func RemoveSmallComponents(im *image.Gray, conn Connectivity, aMin int) *image.Gray {
+	ls, cs := Label(im, conn)
+	var keep []int
+	for _, c := range cs {
+		if c.area >= aMin {
+			keep = append(keep, c.label)
+		}
+	}
+	return ls.Mask(keep...)
+}
@@ -0,0 +1,129 @@
+package vision
+
+import (
+	"image"
+	"screwSort/geometry"
+	"sort"
+	"testing"
+)
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		im    *image.Gray
+		conn  Connectivity
+		areas []int
+	}{
+		{"empty", grayRows(
+			"....",
+			"....",
+		), Connectivity8, nil},
+		{"two blocks", grayRows(
+			"##....",
+			"##..##",
+			"....##",
+		), Connectivity4, []int{4, 4}},
+		// the arms of a U are only joined on the last row, so the first pass gives them different labels
+		{"U shape", grayRows(
+			"#...#",
+			"#...#",
+			"#####",
+		), Connectivity4, []int{9}},
+		{"diagonal with 4-connectivity", grayRows(
+			"#..",
+			".#.",
+			"..#",
+		), Connectivity4, []int{1, 1, 1}},
+		{"diagonal with 8-connectivity", grayRows(
+			"#..",
+			".#.",
+			"..#",
+		), Connectivity8, []int{3}},
+		{"ring around an island", grayRows(
+			"#####",
+			"#...#",
+			"#.#.#",
+			"#...#",
+			"#####",
+		), Connectivity8, []int{1, 16}},
+	}
+	for _, tt := range tests {
+		ls, cs := Label(tt.im, tt.conn)
+		var areas []int
+		for i, c := range cs {
+			if c.Label() != i+1 {
+				t.Errorf("%s: Component %d has label %d", tt.name, i, c.Label())
+			}
+			areas = append(areas, c.Area())
+			for _, p := range c.Points() {
+				if ls.At(p.X, p.Y) != c.Label() {
+					t.Errorf("%s: pixel %v of Component %d has label %d", tt.name, p, c.Label(), ls.At(p.X, p.Y))
+				}
+			}
+		}
+		sort.Ints(areas)
+		if len(areas) != len(tt.areas) {
+			t.Errorf("%s: got areas %v, want %v", tt.name, areas, tt.areas)
+			continue
+		}
+		for i := range areas {
+			if areas[i] != tt.areas[i] {
+				t.Errorf("%s: got areas %v, want %v", tt.name, areas, tt.areas)
+				break
+			}
+		}
+		for y := 0; y < tt.im.Rect.Dy(); y++ {
+			for x := 0; x < tt.im.Rect.Dx(); x++ {
+				if white := tt.im.Pix[y*tt.im.Stride+x] != b; white != (ls.At(x, y) != 0) {
+					t.Errorf("%s: pixel (%d, %d) is white %v but has label %d", tt.name, x, y, white, ls.At(x, y))
+				}
+			}
+		}
+	}
+}
+
+func TestComponentStatistics(t *testing.T) {
+	im := grayRows(
+		"......",
+		".####.",
+		".####.",
+		"......",
+	)
+	_, cs := Label(im, Connectivity8)
+	if len(cs) != 1 {
+		t.Fatalf("got %d Components, want 1", len(cs))
+	}
+	c := cs[0]
+	if c.Area() != 8 || c.Bounds() != image.Rect(1, 1, 5, 3) {
+		t.Errorf("got area %d and bounds %v, want 8 and %v", c.Area(), c.Bounds(), image.Rect(1, 1, 5, 3))
+	}
+	if want := geometry.PointXY(3, 2); c.Centroid().DistanceTo(want) > 1e-12 {
+		t.Errorf("Centroid() = %v, want %v", c.Centroid(), want)
+	}
+	// the variance of the pixel centers {1.5, ..., 4.5} is 1.25 and of {1.5, 2.5} is 0.25
+	if mu20, mu02, mu11 := c.Moments(); mu20 != 1.25 || mu02 != 0.25 || mu11 != 0 {
+		t.Errorf("Moments() = %v, %v, %v, want 1.25, 0.25, 0", mu20, mu02, mu11)
+	}
+	if c.Orientation() != 0 {
+		t.Errorf("Orientation() = %v, want 0", c.Orientation())
+	}
+}
+
+func TestRemoveSmallComponents(t *testing.T) {
+	im := grayRows(
+		"#.....",
+		"...###",
+		"...###",
+	)
+	got := RemoveSmallComponents(im, Connectivity8, 2)
+	want := grayRows(
+		"......",
+		"...###",
+		"...###",
+	)
+	for i := range want.Pix {
+		if got.Pix[i] != want.Pix[i] {
+			t.Fatalf("got %v, want %v", got.Pix, want.Pix)
+		}
+	}
+}
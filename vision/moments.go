@@ -0,0 +1,124 @@
+package vision
+
+import "math"
+
+// Moments describes the area moments of the region enclosed by a Hull up to order 3
+type Moments struct {
+	m [4][4]float64
+}
+
+// Moments returns the Moments of the region enclosed by the Hull, integrated exactly over the polygon
+// by Green's theorem so that they do not depend on its winding
+func (h Hull) Moments() Moments {
+	var m Moments
+	n := len(h.ps)
+	for i, pi := range h.ps {
+		pj := h.ps[(i+1)%n]
+		c := pi.Cross(pj)
+		for p := 0; p <= 3; p++ {
+			for q := 0; p+q <= 3; q++ {
+				s := 0.
+				for k := 0; k <= p; k++ {
+					for l := 0; l <= q; l++ {
+						s += binomial(k+l, l) * binomial(p+q-k-l, q-l) *
+							math.Pow(pj.X(), float64(k)) * math.Pow(pi.X(), float64(p-k)) *
+							math.Pow(pj.Y(), float64(l)) * math.Pow(pi.Y(), float64(q-l))
+					}
+				}
+				m.m[p][q] += c * s
+			}
+		}
+	}
+	for p := 0; p <= 3; p++ {
+		for q := 0; p+q <= 3; q++ {
+			m.m[p][q] /= float64((p+q+2)*(p+q+1)) * binomial(p+q, p)
+		}
+	}
+	if m.m[0][0] < 0 {
+		for p := range m.m {
+			for q := range m.m[p] {
+				m.m[p][q] = -m.m[p][q]
+			}
+		}
+	}
+	return m
+}
+
+// Raw returns the raw moment m_pq, the integral of xᵖyᵠ over the region, for p+q ≤ 3
+func (m Moments) Raw(p, q int) float64 {
+	return m.m[p][q]
+}
+
+// Central returns the central moment μ_pq about the centroid of the region for p+q ≤ 3
+func (m Moments) Central(p, q int) float64 {
+	if m.m[0][0] == 0 {
+		return 0
+	}
+	xBar, yBar := m.m[1][0]/m.m[0][0], m.m[0][1]/m.m[0][0]
+	s := 0.
+	for i := 0; i <= p; i++ {
+		for j := 0; j <= q; j++ {
+			s += binomial(p, i) * binomial(q, j) * math.Pow(-xBar, float64(p-i)) * math.Pow(-yBar, float64(q-j)) * m.m[i][j]
+		}
+	}
+	return s
+}
+
+// Normalized returns the scale-invariant central moment η_pq = μ_pq / μ_00^(1+(p+q)/2) for p+q ≤ 3
+func (m Moments) Normalized(p, q int) float64 {
+	return m.Central(p, q) / math.Pow(m.m[0][0], 1+float64(p+q)/2)
+}
+
+// Hu returns the seven Hu invariants of the region, which do not change under translation, scale, and rotation,
+// and of which only the last changes sign under reflection
+func (m Moments) Hu() [7]float64 {
+	n20, n02, n11 := m.Normalized(2, 0), m.Normalized(0, 2), m.Normalized(1, 1)
+	n30, n03, n21, n12 := m.Normalized(3, 0), m.Normalized(0, 3), m.Normalized(2, 1), m.Normalized(1, 2)
+	a, b := n30+n12, n21+n03
+	return [7]float64{
+		n20 + n02,
+		(n20-n02)*(n20-n02) + 4*n11*n11,
+		(n30-3*n12)*(n30-3*n12) + (3*n21-n03)*(3*n21-n03),
+		a*a + b*b,
+		(n30-3*n12)*a*(a*a-3*b*b) + (3*n21-n03)*b*(3*a*a-b*b),
+		(n20-n02)*(a*a-b*b) + 4*n11*a*b,
+		(3*n21-n03)*a*(a*a-3*b*b) - (n30-3*n12)*b*(3*a*a-b*b),
+	}
+}
+
+// Eccentricity returns the eccentricity of the ellipse with the same second moments as the region,
+// 0 for a disc and approaching 1 for a line
+func (m Moments) Eccentricity() float64 {
+	u20, u02, u11 := m.Central(2, 0), m.Central(0, 2), m.Central(1, 1)
+	mean, r := (u20+u02)/2, math.Hypot((u20-u02)/2, u11)
+	if mean+r <= 0 {
+		return 0
+	}
+	return math.Sqrt(math.Max(0, 1-(mean-r)/(mean+r)))
+}
+
+// Solidity returns the ratio of the area of the Hull to the area of its convex hull
+func (h Hull) Solidity() (float64, error) {
+	c, err := h.Convex()
+	if err != nil {
+		return 0, err
+	}
+	return h.Area() / c.Area(), nil
+}
+
+// Circularity returns 4πA/P² of the Hull, 1 for a disc and smaller for every other shape
+func (h Hull) Circularity() float64 {
+	l := h.Perimeter()
+	if l == 0 {
+		return 0
+	}
+	return 4 * math.Pi * h.Area() / (l * l)
+}
+
+func binomial(n, k int) float64 {
+	c := 1.
+	for i := 1; i <= k; i++ {
+		c = c * float64(n-k+i) / float64(i)
+	}
+	return c
+}
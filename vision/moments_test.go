@@ -0,0 +1,136 @@
+package vision
+
+import (
+	"math"
+	"screwSort/geometry"
+	"testing"
+)
+
+// polygonDisk returns the Hull of the regular polygon with n vertices inscribed in the circle
+func polygonDisk(cx, cy, r float64, n int) Hull {
+	ps := make([]geometry.Point, n)
+	for i := range ps {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		ps[i] = geometry.PointXY(cx+r*math.Cos(a), cy+r*math.Sin(a))
+	}
+	return HullPs(ps)
+}
+
+func TestMomentsRawAndCentral(t *testing.T) {
+	// the 4×2 rectangle [1, 5]×[3, 5] has its centroid at (3, 4)
+	rect := hullXY(1, 3, 5, 3, 5, 5, 1, 5)
+	const r = 10.
+	disk := polygonDisk(20, 30, r, 2000)
+	tests := []struct {
+		name         string
+		h            Hull
+		area, cx, cy float64
+		mu20, mu02   float64
+		eps          float64
+	}{
+		{"rectangle", rect, 8, 3, 4, 8 * 16 / 12., 8 * 4 / 12., 1e-9},
+		{"reversed rectangle", rect.Reverse(), 8, 3, 4, 8 * 16 / 12., 8 * 4 / 12., 1e-9},
+		{"disk", disk, math.Pi * r * r, 20, 30, math.Pi * r * r * r * r / 4, math.Pi * r * r * r * r / 4, 1e-5},
+	}
+	for _, tt := range tests {
+		m := tt.h.Moments()
+		// relative to the size of each moment
+		close := func(got, want, scale float64) bool { return math.Abs(got-want) <= tt.eps*scale }
+		if !close(m.Raw(0, 0), tt.area, tt.area) ||
+			!close(m.Raw(1, 0), tt.area*tt.cx, tt.area*tt.cx) ||
+			!close(m.Raw(0, 1), tt.area*tt.cy, tt.area*tt.cy) {
+			t.Errorf("%s: Raw(0, 0), Raw(1, 0), Raw(0, 1) = %v, %v, %v, want %v, %v, %v",
+				tt.name, m.Raw(0, 0), m.Raw(1, 0), m.Raw(0, 1), tt.area, tt.area*tt.cx, tt.area*tt.cy)
+		}
+		// the parallel axis theorem relates the raw and the central second moments
+		if want := tt.mu20 + tt.area*tt.cx*tt.cx; !close(m.Raw(2, 0), want, want) {
+			t.Errorf("%s: Raw(2, 0) = %v, want %v", tt.name, m.Raw(2, 0), want)
+		}
+		if want := tt.area * tt.cx * tt.cy; !close(m.Raw(1, 1), want, want) {
+			t.Errorf("%s: Raw(1, 1) = %v, want %v", tt.name, m.Raw(1, 1), want)
+		}
+		if !close(m.Central(2, 0), tt.mu20, tt.mu20) || !close(m.Central(0, 2), tt.mu02, tt.mu20) {
+			t.Errorf("%s: Central(2, 0), Central(0, 2) = %v, %v, want %v, %v", tt.name, m.Central(2, 0), m.Central(0, 2), tt.mu20, tt.mu02)
+		}
+		// both shapes are symmetric about their centroid
+		for _, pq := range [][2]int{{1, 0}, {0, 1}, {1, 1}, {3, 0}, {2, 1}, {1, 2}, {0, 3}} {
+			if got := m.Central(pq[0], pq[1]); !close(got, 0, tt.mu20) {
+				t.Errorf("%s: Central(%d, %d) = %v, want 0", tt.name, pq[0], pq[1], got)
+			}
+		}
+		if got, want := m.Normalized(2, 0), tt.mu20/(tt.area*tt.area); !close(got, want, want) {
+			t.Errorf("%s: Normalized(2, 0) = %v, want %v", tt.name, got, want)
+		}
+	}
+
+	if m := HullPs(nil).Moments(); m.Raw(0, 0) != 0 || m.Central(2, 0) != 0 {
+		t.Errorf("Moments of an empty Hull are not zero")
+	}
+}
+
+func TestHuInvariance(t *testing.T) {
+	h := hullXY(0, 0, 7, 1, 9, 5, 4, 4, 2, 8, -1, 3)
+	want := h.Moments().Hu()
+	tests := []struct {
+		name string
+		f    func(geometry.Point) geometry.Point
+	}{
+		{"translated", func(p geometry.Point) geometry.Point { return p.Translate(-40, 25) }},
+		{"rotated", func(p geometry.Point) geometry.Point { return p.Rotate(0.7) }},
+		{"scaled", func(p geometry.Point) geometry.Point { return p.Scale(3.5) }},
+		{"scaled to millimetres", func(p geometry.Point) geometry.Point { return p.Scale(0.04) }},
+		{"rotated and scaled", func(p geometry.Point) geometry.Point { return p.Rotate(-2.1).Scale(0.3).Translate(5, 5) }},
+	}
+	for _, tt := range tests {
+		got := h.Map(tt.f).Moments().Hu()
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 1e-9*math.Abs(want[i]) {
+				t.Errorf("%s: Hu()[%d] = %v, want %v", tt.name, i, got[i], want[i])
+			}
+		}
+	}
+
+	// only the last invariant changes sign under reflection
+	mirrored := h.Map(func(p geometry.Point) geometry.Point { return geometry.PointXY(-p.X(), p.Y()) }).Moments().Hu()
+	for i := range mirrored {
+		w := want[i]
+		if i == 6 {
+			w = -w
+		}
+		if math.Abs(mirrored[i]-w) > 1e-9*math.Abs(w) {
+			t.Errorf("mirrored: Hu()[%d] = %v, want %v", i, mirrored[i], w)
+		}
+	}
+
+	// a disk has η20 = η02 = 1/4π and no other invariant
+	disk := polygonDisk(0, 0, 5, 2000).Moments().Hu()
+	if math.Abs(disk[0]-1/(2*math.Pi)) > 1e-6 {
+		t.Errorf("disk: Hu()[0] = %v, want %v", disk[0], 1/(2*math.Pi))
+	}
+	for i := 1; i < len(disk); i++ {
+		if math.Abs(disk[i]) > 1e-12 {
+			t.Errorf("disk: Hu()[%d] = %v, want 0", i, disk[i])
+		}
+	}
+}
+
+func TestEccentricity(t *testing.T) {
+	tests := []struct {
+		name string
+		h    Hull
+		want float64
+	}{
+		{"disk", polygonDisk(3, 4, 10, 2000), 0},
+		{"square", hullXY(0, 0, 4, 0, 4, 4, 0, 4), 0},
+		// the second moments of a w×h rectangle are proportional to w² and h²
+		{"2:1 rectangle", hullXY(1, 3, 5, 3, 5, 5, 1, 5), math.Sqrt(1 - 4/16.)},
+		{"rotated 2:1 rectangle", hullXY(1, 3, 5, 3, 5, 5, 1, 5).RotateAbout(geometry.PointXY(3, 4), 0.6), math.Sqrt(1 - 4/16.)},
+		{"thin rectangle", hullXY(0, 0, 100, 0, 100, 1, 0, 1), math.Sqrt(1 - 1/1e4)},
+		{"empty", HullPs(nil), 0},
+	}
+	for _, tt := range tests {
+		if got := tt.h.Moments().Eccentricity(); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: Eccentricity() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
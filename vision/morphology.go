@@ -0,0 +1,196 @@
+package vision
+
+import (
+	"image"
+)
+
+// Element describes a structuring element by the offsets of its members from its anchor
+type Element struct {
+	ps []image.Point
+}
+
+// ElementPoints constructs an Element from the offsets of its members
+func ElementPoints(ps ...image.Point) Element {
+	return Element{ps}
+}
+
+// ElementMask constructs an Element from the nonzero pixels of the mask relative to the anchor
+func ElementMask(mask *image.Gray, anchor image.Point) Element {
+	var ps []image.Point
+	for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+		for x := mask.Rect.Min.X; x < mask.Rect.Max.X; x++ {
+			if mask.Pix[mask.PixOffset(x, y)] != b {
+				ps = append(ps, image.Point{X: x - anchor.X, Y: y - anchor.Y})
+			}
+		}
+	}
+	return Element{ps}
+}
+
+// ElementRect constructs a dx×dy rectangular Element anchored at its center
+func ElementRect(dx, dy int) Element {
+	var ps []image.Point
+	for y := -(dy - 1) / 2; y <= dy/2; y++ {
+		for x := -(dx - 1) / 2; x <= dx/2; x++ {
+			ps = append(ps, image.Point{X: x, Y: y})
+		}
+	}
+	return Element{ps}
+}
+
+// ElementDisk constructs a disk-shaped Element of radius r anchored at its center
+func ElementDisk(r int) Element {
+	var ps []image.Point
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				ps = append(ps, image.Point{X: x, Y: y})
+			}
+		}
+	}
+	return Element{ps}
+}
+
+// ElementCross constructs a cross-shaped Element with arms of length r anchored at its center
+func ElementCross(r int) Element {
+	ps := []image.Point{{}}
+	for i := 1; i <= r; i++ {
+		ps = append(ps, image.Point{X: i}, image.Point{X: -i}, image.Point{Y: i}, image.Point{Y: -i})
+	}
+	return Element{ps}
+}
+
+// Points returns the offsets of the members of the Element
+func (e Element) Points() []image.Point {
+	return e.ps
+}
+
+// Reflect returns the Element mirrored through its anchor
+func (e Element) Reflect() Element {
+	ps := make([]image.Point, len(e.ps))
+	for i, p := range e.ps {
+		ps[i] = image.Point{X: -p.X, Y: -p.Y}
+	}
+	return Element{ps}
+}
+
+// ErodeElement returns the grayscale erosion of the image by the Element,
+// the minimum over the members of the Element placed at each pixel
+//
+// Members falling outside the image are ignored
+func ErodeElement(im *image.Gray, e Element, ws ...Workers) *image.Gray {
+	return rankElement(im, e, func(v, u uint8) bool { return v < u }, workersOf(ws))
+}
+
+// DilateElement returns the grayscale dilation of the image by the Element,
+// the maximum over the members of the reflected Element placed at each pixel
+//
+// Members falling outside the image are ignored
+func DilateElement(im *image.Gray, e Element, ws ...Workers) *image.Gray {
+	return rankElement(im, e.Reflect(), func(v, u uint8) bool { return v > u }, workersOf(ws))
+}
+
+// Opening returns the opening of the image by the Element, which removes bright features smaller than it
+func Opening(im *image.Gray, e Element, ws ...Workers) *image.Gray {
+	return DilateElement(ErodeElement(im, e, ws...), e, ws...)
+}
+
+// Closing returns the closing of the image by the Element, which fills dark features smaller than it
+func Closing(im *image.Gray, e Element, ws ...Workers) *image.Gray {
+	return ErodeElement(DilateElement(im, e, ws...), e, ws...)
+}
+
+// TopHat returns the difference of the image and its opening, which keeps bright features smaller than the Element
+func TopHat(im *image.Gray, e Element, ws ...Workers) *image.Gray {
+	return subtract(im, Opening(im, e, ws...), workersOf(ws))
+}
+
+// BlackHat returns the difference of the closing of the image and the image,
+// which keeps dark features smaller than the Element
+func BlackHat(im *image.Gray, e Element, ws ...Workers) *image.Gray {
+	return subtract(Closing(im, e, ws...), im, workersOf(ws))
+}
+
+// MorphologicalGradient returns the difference of the dilation and the erosion of the image,
+// which highlights the outlines of features
+func MorphologicalGradient(im *image.Gray, e Element, ws ...Workers) *image.Gray {
+	return subtract(DilateElement(im, e, ws...), ErodeElement(im, e, ws...), workersOf(ws))
+}
+
+// HitOrMiss returns the binary image that is white where every member of hit lands on a white pixel
+// and every member of miss lands on a black pixel of the binary image
+//
+// Pixels outside the image are black
+func HitOrMiss(im *image.Gray, hit, miss Element, ws ...Workers) *image.Gray {
+	out := image.NewGray(im.Rect)
+	at := func(x, y int) uint8 {
+		if !(image.Point{X: x, Y: y}).In(im.Rect) {
+			return b
+		}
+		return im.Pix[im.PixOffset(x, y)]
+	}
+	workersOf(ws).parallelRows(im.Rect.Dy(), func(y0, y1 int) {
+		for y := im.Rect.Min.Y + y0; y < im.Rect.Min.Y+y1; y++ {
+			for x := im.Rect.Min.X; x < im.Rect.Max.X; x++ {
+				match := true
+				for _, p := range hit.ps {
+					if at(x+p.X, y+p.Y) == b {
+						match = false
+						break
+					}
+				}
+				for _, p := range miss.ps {
+					if !match {
+						break
+					}
+					if at(x+p.X, y+p.Y) != b {
+						match = false
+					}
+				}
+				if match {
+					out.Pix[out.PixOffset(x, y)] = w
+				}
+			}
+		}
+	})
+	return out
+}
+
+// rankElement replaces each pixel with the value over the members of the Element that is preferred by better
+func rankElement(im *image.Gray, e Element, better func(v, u uint8) bool, ws Workers) *image.Gray {
+	out := image.NewGray(im.Rect)
+	ws.parallelRows(im.Rect.Dy(), func(y0, y1 int) {
+		for y := im.Rect.Min.Y + y0; y < im.Rect.Min.Y+y1; y++ {
+			for x := im.Rect.Min.X; x < im.Rect.Max.X; x++ {
+				v, found := im.Pix[im.PixOffset(x, y)], false
+				for _, p := range e.ps {
+					q := image.Point{X: x + p.X, Y: y + p.Y}
+					if !q.In(im.Rect) {
+						continue
+					}
+					if u := im.Pix[im.PixOffset(q.X, q.Y)]; !found || better(u, v) {
+						v, found = u, true
+					}
+				}
+				out.Pix[out.PixOffset(x, y)] = v
+			}
+		}
+	})
+	return out
+}
+
+// subtract returns the saturating difference of the images
+func subtract(im, o *image.Gray, ws Workers) *image.Gray {
+	out := image.NewGray(im.Rect)
+	ws.parallelRows(im.Rect.Dy(), func(y0, y1 int) {
+		for y := im.Rect.Min.Y + y0; y < im.Rect.Min.Y+y1; y++ {
+			for x := im.Rect.Min.X; x < im.Rect.Max.X; x++ {
+				v, u := im.Pix[im.PixOffset(x, y)], o.Pix[o.PixOffset(x, y)]
+				if v > u {
+					out.Pix[out.PixOffset(x, y)] = v - u
+				}
+			}
+		}
+	})
+	return out
+}
@@ -0,0 +1,214 @@
+package vision
+
+import (
+	"bytes"
+	"image"
+	"sort"
+	"testing"
+)
+
+// sortedPoints returns the points in raster order
+func sortedPoints(ps []image.Point) []image.Point {
+	qs := append([]image.Point(nil), ps...)
+	sort.Slice(qs, func(i, j int) bool { return qs[i].Y < qs[j].Y || qs[i].Y == qs[j].Y && qs[i].X < qs[j].X })
+	return qs
+}
+
+func TestElements(t *testing.T) {
+	pt := func(x, y int) image.Point { return image.Point{X: x, Y: y} }
+	tests := []struct {
+		name string
+		e    Element
+		want []image.Point
+	}{
+		{"ElementRect 3×2", ElementRect(3, 2), []image.Point{pt(-1, 0), pt(0, 0), pt(1, 0), pt(-1, 1), pt(0, 1), pt(1, 1)}},
+		{"ElementRect 1×1", ElementRect(1, 1), []image.Point{pt(0, 0)}},
+		{"ElementDisk 1", ElementDisk(1), []image.Point{pt(0, -1), pt(-1, 0), pt(0, 0), pt(1, 0), pt(0, 1)}},
+		{"ElementCross 1", ElementCross(1), []image.Point{pt(0, -1), pt(-1, 0), pt(0, 0), pt(1, 0), pt(0, 1)}},
+		{"ElementMask", ElementMask(grayRows(
+			"#..",
+			".##",
+		), image.Pt(1, 1)), []image.Point{pt(-1, -1), pt(0, 0), pt(1, 0)}},
+		{"ElementPoints reflected", ElementPoints(pt(2, 1), pt(0, 0), pt(-1, 3)).Reflect(), []image.Point{pt(1, -3), pt(-2, -1), pt(0, 0)}},
+	}
+	for _, tt := range tests {
+		got, want := sortedPoints(tt.e.Points()), sortedPoints(tt.want)
+		if len(got) != len(want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, want)
+				break
+			}
+		}
+	}
+	// a disk of radius r holds the offsets within r of its center
+	for r, n := range map[int]int{0: 1, 2: 13, 3: 29} {
+		if got := len(ElementDisk(r).Points()); got != n {
+			t.Errorf("ElementDisk(%d) has %d members, want %d", r, got, n)
+		}
+	}
+	if got := len(ElementCross(3).Points()); got != 13 {
+		t.Errorf("ElementCross(3) has %d members, want 13", got)
+	}
+}
+
+// rankGeneric returns the minimum or maximum of the image over the members of the Element placed at every pixel,
+// ignoring those outside the image
+func rankGeneric(im *image.Gray, e Element, max bool) *image.Gray {
+	out := image.NewGray(im.Rect)
+	for y := im.Rect.Min.Y; y < im.Rect.Max.Y; y++ {
+		for x := im.Rect.Min.X; x < im.Rect.Max.X; x++ {
+			v, found := im.GrayAt(x, y).Y, false
+			for _, p := range e.Points() {
+				q := image.Pt(x+p.X, y+p.Y)
+				if !q.In(im.Rect) {
+					continue
+				}
+				if u := im.GrayAt(q.X, q.Y).Y; !found || max && u > v || !max && u < v {
+					v, found = u, true
+				}
+			}
+			out.Pix[out.PixOffset(x, y)] = v
+		}
+	}
+	return out
+}
+
+// morphologyElements returns Elements of several shapes that contain their anchor
+func morphologyElements() map[string]Element {
+	return map[string]Element{
+		"disk":       ElementDisk(2),
+		"rect":       ElementRect(4, 3),
+		"cross":      ElementCross(1),
+		"asymmetric": ElementPoints(image.Pt(0, 0), image.Pt(1, 0), image.Pt(2, 1), image.Pt(0, -2)),
+	}
+}
+
+func TestErodeDilateElement(t *testing.T) {
+	im := parallelImage()
+	for name, e := range morphologyElements() {
+		if got, want := ErodeElement(im, e), rankGeneric(im, e, false); !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%s: ErodeElement differs from the minimum over the Element", name)
+		}
+		// dilation takes the maximum over the reflected Element
+		dilated := DilateElement(im, e)
+		if want := rankGeneric(im, e.Reflect(), true); !bytes.Equal(dilated.Pix, want.Pix) {
+			t.Errorf("%s: DilateElement differs from the maximum over the reflected Element", name)
+		}
+		// and is dual to erosion by the reflected Element
+		if want := invert(ErodeElement(invert(im), e.Reflect())); !bytes.Equal(dilated.Pix, want.Pix) {
+			t.Errorf("%s: DilateElement is not dual to ErodeElement", name)
+		}
+	}
+}
+
+func TestOpeningClosing(t *testing.T) {
+	im := parallelImage()
+	for name, e := range morphologyElements() {
+		opened, closed := Opening(im, e), Closing(im, e)
+		if again := Opening(opened, e); !bytes.Equal(again.Pix, opened.Pix) {
+			t.Errorf("%s: Opening is not idempotent", name)
+		}
+		if again := Closing(closed, e); !bytes.Equal(again.Pix, closed.Pix) {
+			t.Errorf("%s: Closing is not idempotent", name)
+		}
+		for i, v := range im.Pix {
+			if opened.Pix[i] > v || closed.Pix[i] < v {
+				t.Errorf("%s: pixel %d is %d with Opening %d and Closing %d", name, i, v, opened.Pix[i], closed.Pix[i])
+				break
+			}
+		}
+
+		// the hats and the gradient are exact differences since the Opening is at most and the Closing at least the image
+		topHat, blackHat, gradient := TopHat(im, e), BlackHat(im, e), MorphologicalGradient(im, e)
+		eroded, dilated := ErodeElement(im, e), DilateElement(im, e)
+		for i, v := range im.Pix {
+			if topHat.Pix[i] != v-opened.Pix[i] {
+				t.Errorf("%s: TopHat at pixel %d = %d, want %d - %d", name, i, topHat.Pix[i], v, opened.Pix[i])
+				break
+			}
+			if blackHat.Pix[i] != closed.Pix[i]-v {
+				t.Errorf("%s: BlackHat at pixel %d = %d, want %d - %d", name, i, blackHat.Pix[i], closed.Pix[i], v)
+				break
+			}
+			if gradient.Pix[i] != dilated.Pix[i]-eroded.Pix[i] {
+				t.Errorf("%s: MorphologicalGradient at pixel %d = %d, want %d - %d", name, i, gradient.Pix[i], dilated.Pix[i], eroded.Pix[i])
+				break
+			}
+		}
+	}
+
+	// a dot smaller than the Element is removed by the Opening and kept by the TopHat alone
+	dots := grayRows(
+		"..........",
+		".#........",
+		"......###.",
+		"......###.",
+		"......###.",
+		"..........",
+	)
+	want := grayRows(
+		"..........",
+		".#........",
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+	)
+	if got := TopHat(dots, ElementRect(3, 3)); !bytes.Equal(got.Pix, want.Pix) {
+		t.Errorf("TopHat = %v, want %v", got.Pix, want.Pix)
+	}
+}
+
+func TestHitOrMiss(t *testing.T) {
+	im := grayRows(
+		"..........",
+		".#....###.",
+		"......###.",
+		"...#..###.",
+		"..........",
+		"#.........",
+	)
+	neighbors := ElementPoints(image.Pt(-1, -1), image.Pt(0, -1), image.Pt(1, -1), image.Pt(-1, 0),
+		image.Pt(1, 0), image.Pt(-1, 1), image.Pt(0, 1), image.Pt(1, 1))
+	tests := []struct {
+		name      string
+		hit, miss Element
+		want      *image.Gray
+	}{
+		// pixels beyond the border are black, so the pixel in the corner is isolated too
+		{"isolated pixels", ElementPoints(image.Pt(0, 0)), neighbors, grayRows(
+			"..........",
+			".#........",
+			"..........",
+			"...#......",
+			"..........",
+			"#.........",
+		)},
+		{"top left corners", ElementPoints(image.Pt(0, 0), image.Pt(1, 0), image.Pt(0, 1)),
+			ElementPoints(image.Pt(-1, 0), image.Pt(0, -1), image.Pt(-1, -1)), grayRows(
+				"..........",
+				"......#...",
+				"..........",
+				"..........",
+				"..........",
+				"..........",
+			)},
+		{"inside of a block", ElementRect(3, 3), ElementPoints(), grayRows(
+			"..........",
+			"..........",
+			".......#..",
+			"..........",
+			"..........",
+			"..........",
+		)},
+	}
+	for _, tt := range tests {
+		if got := HitOrMiss(im, tt.hit, tt.miss); !bytes.Equal(got.Pix, tt.want.Pix) {
+			t.Errorf("%s: got %v, want %v", tt.name, got.Pix, tt.want.Pix)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package vision
+
+import (
+	"runtime"
+	"screwSort/utility"
+	"sync"
+)
+
+// Workers is the number of goroutines that a vision filter splits the rows of an image across,
+// passed as its optional last argument, or runtime.GOMAXPROCS(0) if it is omitted or not positive
+//
+// Results do not depend on Workers
+type Workers int
+
+// bandRowsMin is the fewest rows given to a goroutine so that small images are not split
+const bandRowsMin = 16
+
+// workersOf returns the Workers passed as the optional last argument of a filter, or 0 if there are none
+func workersOf(ws []Workers) Workers {
+	if len(ws) == 0 {
+		return 0
+	}
+	return ws[0]
+}
+
+// parallelRows calls f on consecutive bands of the rows {0, ..., dy-1} across the Workers
+// and returns once all have finished
+//
+// f must only write the rows of its band, while it may read any row of its input, so that neighborhood
+// operations see the unmodified rows beyond the band as their halo
+func (ws Workers) parallelRows(dy int, f func(y0, y1 int)) {
+	n := int(ws)
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	n = utility.Min(n, (dy+bandRowsMin-1)/bandRowsMin)
+	if n <= 1 {
+		f(0, dy)
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		y0, y1 := i*dy/n, (i+1)*dy/n
+		go func() {
+			defer wg.Done()
+			f(y0, y1)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,114 @@
+package vision
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+// parallelImage returns a gray test image tall enough to be split across several Workers
+func parallelImage() *image.Gray {
+	return ToGray(testImages(97, 131)["Gray"])
+}
+
+// surfaceThresholdOf returns a function thresholding the image by a Surface unless the Surface failed
+func surfaceThresholdOf(im *image.Gray, ws Workers) func(Surface, error) (*image.Gray, error) {
+	return func(s Surface, err error) (*image.Gray, error) {
+		if err != nil {
+			return nil, err
+		}
+		return SurfaceThreshold(im, s, ws)
+	}
+}
+
+func TestFiltersIndependentOfWorkers(t *testing.T) {
+	im := parallelImage()
+	bin := Threshold(im, 128)
+	element := ElementDisk(2)
+	tests := []struct {
+		name string
+		f    func(ws Workers) (*image.Gray, error)
+	}{
+		{"Threshold", func(ws Workers) (*image.Gray, error) { return Threshold(im, 100, ws), nil }},
+		{"InverseThreshold", func(ws Workers) (*image.Gray, error) { return InverseThreshold(im, 100, ws), nil }},
+		{"Erode", func(ws Workers) (*image.Gray, error) { return Erode(bin, 3, ws) }},
+		{"Dilate", func(ws Workers) (*image.Gray, error) { return Dilate(bin, 3, ws) }},
+		{"ErodeElement", func(ws Workers) (*image.Gray, error) { return ErodeElement(im, element, ws), nil }},
+		{"Opening", func(ws Workers) (*image.Gray, error) { return Opening(im, element, ws), nil }},
+		{"Closing", func(ws Workers) (*image.Gray, error) { return Closing(im, element, ws), nil }},
+		{"GaussianBlur", func(ws Workers) (*image.Gray, error) { return GaussianBlur(im, 1.5, ws) }},
+		{"BoxBlur", func(ws Workers) (*image.Gray, error) { return BoxBlur(im, 2, ws) }},
+		{"MedianBlur", func(ws Workers) (*image.Gray, error) { return MedianBlur(im, 2, ws) }},
+		{"BilateralFilter", func(ws Workers) (*image.Gray, error) { return BilateralFilter(im, 2, 30, ws) }},
+		{"Canny", func(ws Workers) (*image.Gray, error) { return Canny(im, 1, 20, 60, ws) }},
+		{"MeanSurface", func(ws Workers) (*image.Gray, error) { return surfaceThresholdOf(im, ws)(MeanSurface(im, 7, 5, ws)) }},
+		{"GaussianSurface", func(ws Workers) (*image.Gray, error) {
+			return surfaceThresholdOf(im, ws)(GaussianSurface(im, 4, 5, ws))
+		}},
+		{"NiblackSurface", func(ws Workers) (*image.Gray, error) {
+			return surfaceThresholdOf(im, ws)(NiblackSurface(im, 7, -0.2, ws))
+		}},
+		{"SauvolaSurface", func(ws Workers) (*image.Gray, error) {
+			return surfaceThresholdOf(im, ws)(SauvolaSurface(im, 7, 0.2, 128, ws))
+		}},
+	}
+	for _, tt := range tests {
+		want, err := tt.f(1)
+		if err != nil {
+			t.Errorf("%s with 1 worker: %v", tt.name, err)
+			continue
+		}
+		for _, ws := range []Workers{2, 4, 7} {
+			got, err := tt.f(ws)
+			if err != nil || !bytes.Equal(got.Pix, want.Pix) {
+				t.Errorf("%s with %d workers differs from 1 worker: %v", tt.name, ws, err)
+			}
+		}
+	}
+}
+
+func TestHullsIndependentOfWorkers(t *testing.T) {
+	im := parallelImage()
+	blurred, err := GaussianBlur(im, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := Threshold(blurred, 128)
+	tests := []struct {
+		name string
+		f    func(ws Workers) []Hull
+	}{
+		{"Hulls", func(ws Workers) []Hull { return Hulls(bin, 0, ws) }},
+		{"SuperHulls", func(ws Workers) []Hull { return SuperHulls(im, AutoLevel, 0, ws) }},
+		{"Pipeline", func(ws Workers) []Hull { return PipelineLevel(AutoLevel, 0).Smooth(1).Workers(ws).Hulls(im) }},
+	}
+	for _, tt := range tests {
+		want := tt.f(1)
+		if len(want) == 0 {
+			t.Errorf("%s: no Hulls to compare", tt.name)
+		}
+		for _, ws := range []Workers{2, 4, 7} {
+			if got := tt.f(ws); !equalHulls(got, want) {
+				t.Errorf("%s with %d workers differs from 1 worker", tt.name, ws)
+			}
+		}
+	}
+}
+
+// equalHulls reports whether the Hulls have exactly the same points in the same order
+func equalHulls(hs, gs []Hull) bool {
+	if len(hs) != len(gs) {
+		return false
+	}
+	for i := range hs {
+		if hs[i].Truncated() != gs[i].Truncated() || len(hs[i].Ps()) != len(gs[i].Ps()) {
+			return false
+		}
+		for j, p := range hs[i].Ps() {
+			if p != gs[i].Ps()[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package vision
+
+import "image"
+
+// Pipeline describes the steps of extracting sub-pixel contours from a gray image:
+// optional Gaussian pre-smoothing, iso-level selection, and tracing
+type Pipeline struct {
+	sigma   float64
+	level   float64
+	aMin    float64
+	workers Workers
+}
+
+// PipelineLevel constructs a Pipeline that traces the iso-contours at the level, which may be AutoLevel,
+// and keeps those enclosing at least aMin pixels²
+func PipelineLevel(level, aMin float64) Pipeline {
+	return Pipeline{level: level, aMin: aMin}
+}
+
+// Smooth returns a new Pipeline that pre-smooths the image with a Gaussian of standard deviation sigma
+// before tracing, or does not smooth if sigma is 0
+//
+// The smoothed values are kept unquantized so that the sub-pixel interpolation is not limited to 8 bits
+func (p Pipeline) Smooth(sigma float64) Pipeline {
+	p.sigma = sigma
+	return p
+}
+
+// Workers returns a new Pipeline that splits the smoothing and tracing across the number of goroutines
+func (p Pipeline) Workers(ws Workers) Pipeline {
+	p.workers = ws
+	return p
+}
+
+// Hulls runs the Pipeline on the image and returns the traced Hulls
+func (p Pipeline) Hulls(im *image.Gray) []Hull {
+	vm := p.level
+	if vm == AutoLevel {
+		vm = float64(OtsuThreshold(im)) + 0.5
+	}
+	if p.sigma <= 0 {
+		return SuperHulls(im, vm, p.aMin, p.workers)
+	}
+	s := Surface{im.Rect, gaussianValues(im, p.sigma, p.workers)}
+	value := func(x, y int) float64 {
+		return s.At(x+im.Rect.Min.X, y+im.Rect.Min.Y)
+	}
+	return superHulls(im.Rect, value, func(int, int) float64 { return vm }, p.aMin, p.workers)
+}
+
+// Regions runs the Pipeline on the image and returns the traced Regions with their holes
+func (p Pipeline) Regions(im *image.Gray) []Region {
+	return Regions(p.Hulls(im))
+}
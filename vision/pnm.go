@@ -0,0 +1,245 @@
+package vision
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+// ErrPnm is returned when a PGM or PPM stream is malformed
+var ErrPnm = errors.New("vision: malformed pnm")
+
+// pnmPixelsMax is the largest number of pixels a PGM or PPM header may declare,
+// so that an untrusted header cannot request an arbitrarily large allocation
+const pnmPixelsMax = 1 << 26
+
+func init() {
+	for _, m := range []string{"P2", "P3", "P5", "P6"} {
+		image.RegisterFormat("pnm", m, DecodePnm, DecodePnmConfig)
+	}
+}
+
+type pnmHeader struct {
+	magic                 string
+	width, height, maxVal int
+}
+
+// DecodePnm decodes a binary or plain PGM or PPM stream
+//
+// Gray streams with a maximum value above 255 decode to *image.Gray16 and color ones to *image.RGBA64,
+// with the values stretched to the full 16-bit range
+func DecodePnm(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readPnmHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	channels := 1
+	if h.magic == "P3" || h.magic == "P6" {
+		channels = 3
+	}
+	wide := h.maxVal > 255
+
+	// the values are only allocated as the data arrives, so a short stream fails before a large allocation
+	n := h.width * h.height * channels
+	var vs []int
+	switch h.magic {
+	case "P2", "P3":
+		for len(vs) < n {
+			v, err := readPnmInt(br)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+	default:
+		size := 1
+		if wide {
+			size = 2
+		}
+		bs, err := io.ReadAll(io.LimitReader(br, int64(n*size)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrPnm, err)
+		}
+		if len(bs) < n*size {
+			return nil, fmt.Errorf("%w: %d bytes of data, want %d", ErrPnm, len(bs), n*size)
+		}
+		vs = make([]int, n)
+		for i := range vs {
+			if wide {
+				vs[i] = int(bs[2*i])<<8 | int(bs[2*i+1])
+			} else {
+				vs[i] = int(bs[i])
+			}
+		}
+	}
+
+	rect := image.Rect(0, 0, h.width, h.height)
+	scale := func(v int, top int) int {
+		if v > h.maxVal {
+			v = h.maxVal
+		}
+		return (v*top + h.maxVal/2) / h.maxVal
+	}
+	switch {
+	case channels == 1 && !wide:
+		out := image.NewGray(rect)
+		for i, v := range vs {
+			out.Pix[i] = uint8(scale(v, 0xff))
+		}
+		return out, nil
+	case channels == 1:
+		out := image.NewGray16(rect)
+		for i, v := range vs {
+			v = scale(v, 0xffff)
+			out.Pix[2*i], out.Pix[2*i+1] = uint8(v>>8), uint8(v)
+		}
+		return out, nil
+	case !wide:
+		out := image.NewRGBA(rect)
+		for i := 0; i < h.width*h.height; i++ {
+			out.Pix[4*i] = uint8(scale(vs[3*i], 0xff))
+			out.Pix[4*i+1] = uint8(scale(vs[3*i+1], 0xff))
+			out.Pix[4*i+2] = uint8(scale(vs[3*i+2], 0xff))
+			out.Pix[4*i+3] = 0xff
+		}
+		return out, nil
+	default:
+		out := image.NewRGBA64(rect)
+		for i := 0; i < h.width*h.height; i++ {
+			out.SetRGBA64(i%h.width, i/h.width, color.RGBA64{
+				R: uint16(scale(vs[3*i], 0xffff)),
+				G: uint16(scale(vs[3*i+1], 0xffff)),
+				B: uint16(scale(vs[3*i+2], 0xffff)),
+				A: 0xffff,
+			})
+		}
+		return out, nil
+	}
+}
+
+// DecodePnmConfig returns the dimensions and color model of a PGM or PPM stream without decoding it
+func DecodePnmConfig(r io.Reader) (image.Config, error) {
+	h, err := readPnmHeader(bufio.NewReader(r))
+	if err != nil {
+		return image.Config{}, err
+	}
+	var m color.Model
+	switch {
+	case (h.magic == "P2" || h.magic == "P5") && h.maxVal > 255:
+		m = color.Gray16Model
+	case h.magic == "P2" || h.magic == "P5":
+		m = color.GrayModel
+	case h.maxVal > 255:
+		m = color.RGBA64Model
+	default:
+		m = color.RGBAModel
+	}
+	return image.Config{ColorModel: m, Width: h.width, Height: h.height}, nil
+}
+
+// EncodePnm writes the image as a binary PGM if it is gray or a binary PPM otherwise,
+// with 16-bit samples if the image is *image.Gray16 or *image.RGBA64
+func EncodePnm(wr io.Writer, im image.Image) error {
+	bw := bufio.NewWriter(wr)
+	b := im.Bounds()
+	_, gray := im.(*image.Gray)
+	_, gray16 := im.(*image.Gray16)
+	_, rgba64 := im.(*image.RGBA64)
+	wide := gray16 || rgba64
+
+	magic, maxVal := "P6", 255
+	if gray || gray16 {
+		magic = "P5"
+	}
+	if wide {
+		maxVal = 65535
+	}
+	if _, err := fmt.Fprintf(bw, "%s\n%d %d\n%d\n", magic, b.Dx(), b.Dy(), maxVal); err != nil {
+		return err
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var vs []uint32
+			if magic == "P5" {
+				vs = []uint32{uint32(color.Gray16Model.Convert(im.At(x, y)).(color.Gray16).Y)}
+			} else {
+				r, g, bl, _ := im.At(x, y).RGBA()
+				vs = []uint32{r, g, bl}
+			}
+			for _, v := range vs {
+				if err := bw.WriteByte(uint8(v >> 8)); err != nil {
+					return err
+				}
+				if wide {
+					if err := bw.WriteByte(uint8(v)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func readPnmHeader(br *bufio.Reader) (pnmHeader, error) {
+	var h pnmHeader
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return h, fmt.Errorf("%w: %v", ErrPnm, err)
+	}
+	h.magic = string(magic)
+	switch h.magic {
+	case "P2", "P3", "P5", "P6":
+	default:
+		return h, fmt.Errorf("%w: unsupported magic %q", ErrPnm, h.magic)
+	}
+
+	var err error
+	for _, v := range []*int{&h.width, &h.height, &h.maxVal} {
+		if *v, err = readPnmInt(br); err != nil {
+			return h, err
+		}
+	}
+	if h.width <= 0 || h.height <= 0 || h.maxVal <= 0 || h.maxVal > 65535 {
+		return h, fmt.Errorf("%w: invalid header %+v", ErrPnm, h)
+	}
+	if h.width > pnmPixelsMax/h.height {
+		return h, fmt.Errorf("%w: %d×%d pixels exceed the maximum of %d", ErrPnm, h.width, h.height, pnmPixelsMax)
+	}
+	return h, nil
+}
+
+// readPnmInt reads a decimal integer skipping whitespace and comments, consuming the single whitespace after it
+func readPnmInt(br *bufio.Reader) (int, error) {
+	var ds []byte
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(ds) > 0 {
+				break
+			}
+			return 0, fmt.Errorf("%w: %v", ErrPnm, err)
+		}
+		switch {
+		case c == '#' && len(ds) == 0:
+			if _, err = br.ReadString('\n'); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrPnm, err)
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if len(ds) > 0 {
+				return strconv.Atoi(string(ds))
+			}
+		case '0' <= c && c <= '9':
+			ds = append(ds, c)
+		default:
+			return 0, fmt.Errorf("%w: unexpected byte %q", ErrPnm, c)
+		}
+	}
+	return strconv.Atoi(string(ds))
+}
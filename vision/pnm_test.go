@@ -0,0 +1,78 @@
+package vision
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestPnmRoundTrip(t *testing.T) {
+	r := image.Rect(0, 0, 5, 3)
+	gray, gray16, rgba, rgba64 := image.NewGray(r), image.NewGray16(r), image.NewRGBA(r), image.NewRGBA64(r)
+	for y := 0; y < r.Dy(); y++ {
+		for x := 0; x < r.Dx(); x++ {
+			v := uint16(x*9000 + y*700 + 3)
+			gray.SetGray(x, y, color.Gray{Y: uint8(v >> 8)})
+			gray16.SetGray16(x, y, color.Gray16{Y: v})
+			rgba.SetRGBA(x, y, color.RGBA{R: uint8(v >> 8), G: uint8(x), B: uint8(y), A: 0xff})
+			rgba64.SetRGBA64(x, y, color.RGBA64{R: v, G: uint16(x), B: uint16(y), A: 0xffff})
+		}
+	}
+	for name, im := range map[string]image.Image{"Gray": gray, "Gray16": gray16, "RGBA": rgba, "RGBA64": rgba64} {
+		var buf bytes.Buffer
+		if err := EncodePnm(&buf, im); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := DecodePnm(&buf)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		for y := 0; y < r.Dy(); y++ {
+			for x := 0; x < r.Dx(); x++ {
+				if got.At(x, y) != im.At(x, y) {
+					t.Errorf("%s: pixel (%d, %d) is %v, want %v", name, x, y, got.At(x, y), im.At(x, y))
+				}
+			}
+		}
+	}
+}
+
+func TestDecodePnmMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unsupported magic", "P4\n2 2\n"},
+		{"zero width", "P5\n0 2\n255\n"},
+		{"maximum value above 16 bits", "P5\n1 1\n65536\n\x00"},
+		{"too many pixels", "P5\n100000 100000\n255\n\x00\x00"},
+		{"product overflows", "P5\n4611686018427387904 4\n255\n\x00"},
+		{"short binary data", "P5\n4 4\n255\n\x00\x01\x02"},
+		{"short wide binary data", "P6\n2 1\n65535\n\x00\x01\x02\x03\x04\x05\x06"},
+		{"short plain data", "P2\n2 2\n255\n1 2 3"},
+		{"bad plain value", "P2\n1 1\n255\nx"},
+	}
+	for _, tt := range tests {
+		if _, err := DecodePnm(strings.NewReader(tt.data)); !errors.Is(err, ErrPnm) {
+			t.Errorf("%s: got %v, want ErrPnm", tt.name, err)
+		}
+	}
+}
+
+func TestDecodePnmPlain(t *testing.T) {
+	im, err := DecodePnm(strings.NewReader("P2\n# a comment\n3 1\n4\n0 2 4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, ok := im.(*image.Gray)
+	if !ok {
+		t.Fatalf("got %T, want *image.Gray", im)
+	}
+	if want := []uint8{0, 128, 255}; !bytes.Equal(g.Pix, want) {
+		t.Errorf("got %v, want %v", g.Pix, want)
+	}
+}
@@ -0,0 +1,153 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"screwSort/geometry"
+	"sort"
+)
+
+// Region describes an outer Hull along with the Hulls of the holes directly inside it
+type Region struct {
+	outer Hull
+	holes []Hull
+}
+
+// RegionHulls constructs a Region from its outer Hull and the Hulls of its holes,
+// orienting the outer Hull counterclockwise and the holes clockwise
+func RegionHulls(outer Hull, holes ...Hull) Region {
+	hs := make([]Hull, len(holes))
+	for i, h := range holes {
+		hs[i] = h.Orient(true)
+	}
+	return Region{outer.Orient(false), hs}
+}
+
+// Outer returns the outer boundary of the Region
+func (r Region) Outer() Hull {
+	return r.outer
+}
+
+// Truncated returns whether the outer boundary of the Region touches the border of the image
+func (r Region) Truncated() bool {
+	return r.outer.truncated
+}
+
+// Holes returns the boundaries of the holes of the Region
+func (r Region) Holes() []Hull {
+	return r.holes
+}
+
+// Area returns the area of the Region with the area of its holes subtracted
+func (r Region) Area() float64 {
+	a := r.outer.Area()
+	for _, h := range r.holes {
+		a -= h.Area()
+	}
+	return a
+}
+
+// Centroid returns the centroid of the Region with its holes taken into account
+func (r Region) Centroid() geometry.Point {
+	ao := r.outer.Area()
+	c := r.outer.Centroid().Scale(ao)
+	a := ao
+	for _, h := range r.holes {
+		ah := h.Area()
+		c = c.Subtract(h.Centroid().Scale(ah))
+		a -= ah
+	}
+	if a <= 0 {
+		return r.outer.Centroid()
+	}
+	return c.Scale(1 / a)
+}
+
+// OuterDiameter returns the diameter of the circle with the same area as the outer boundary
+func (r Region) OuterDiameter() float64 {
+	return equivalentDiameter(r.outer.Area())
+}
+
+// HoleDiameter returns the diameter of the circle with the same area as the largest hole
+// or 0 if the Region has no holes
+func (r Region) HoleDiameter() float64 {
+	a := 0.
+	for _, h := range r.holes {
+		a = math.Max(a, h.Area())
+	}
+	return equivalentDiameter(a)
+}
+
+// Contains returns whether the Point is inside the outer boundary and outside all holes
+func (r Region) Contains(p geometry.Point) bool {
+	if !r.outer.Contains(p) {
+		return false
+	}
+	for _, h := range r.holes {
+		if h.Contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Regions arranges the Hulls into a contour tree and returns a Region for every outer boundary
+//
+// A Hull nested inside an even number of other Hulls is an outer boundary and one nested inside
+// an odd number is a hole of the smallest Hull containing it
+func Regions(hs []Hull) []Region {
+	n := len(hs)
+	is := make([]int, n)
+	as := make([]float64, n)
+	for i, h := range hs {
+		is[i] = i
+		as[i] = h.Area()
+	}
+	sort.SliceStable(is, func(i, j int) bool {
+		return as[is[i]] > as[is[j]]
+	})
+
+	parents := make([]int, n)
+	depths := make([]int, n)
+	for k, i := range is {
+		parents[i] = -1
+		for l := k - 1; l >= 0; l-- {
+			j := is[l]
+			if len(hs[i].ps) > 0 && hs[j].Contains(hs[i].ps[0]) {
+				parents[i] = j
+				depths[i] = depths[j] + 1
+				break
+			}
+		}
+	}
+
+	rm := make(map[int]int)
+	var rs []Region
+	for _, i := range is {
+		if depths[i]%2 == 0 {
+			rm[i] = len(rs)
+			rs = append(rs, RegionHulls(hs[i]))
+		}
+	}
+	for _, i := range is {
+		if depths[i]%2 == 1 {
+			r := &rs[rm[parents[i]]]
+			r.holes = append(r.holes, hs[i].Orient(true))
+		}
+	}
+	return rs
+}
+
+// HullRegions traces the white regions of the binary image along with their holes
+func HullRegions(im *image.Gray, aMin float64, ws ...Workers) []Region {
+	return Regions(Hulls(im, aMin, ws...))
+}
+
+// SuperHullRegions traces the dark regions of the image along with their holes at sub-pixel accuracy
+func SuperHullRegions(im *image.Gray, vm, aMin float64, ws ...Workers) []Region {
+	return Regions(SuperHulls(im, vm, aMin, ws...))
+}
+
+func equivalentDiameter(a float64) float64 {
+	return 2 * math.Sqrt(a/math.Pi)
+}
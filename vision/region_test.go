@@ -0,0 +1,108 @@
+package vision
+
+import (
+	"math"
+	"screwSort/geometry"
+	"testing"
+)
+
+func TestRegionHoles(t *testing.T) {
+	outer := hullXY(0, 0, 10, 0, 10, 10, 0, 10)
+	left := hullXY(1, 1, 1, 5, 3, 5, 3, 1)
+	right := hullXY(6, 6, 9, 6, 9, 9, 6, 9)
+	tests := []struct {
+		name         string
+		r            Region
+		area         float64
+		centroid     geometry.Point
+		holeDiameter float64
+	}{
+		{"no holes", RegionHulls(outer), 100, geometry.PointXY(5, 5), 0},
+		{"one hole", RegionHulls(outer, left), 92, geometry.PointXY((500-16)/92., (500-24)/92.), equivalentDiameter(8)},
+		{"two holes", RegionHulls(outer, left, right), 83,
+			geometry.PointXY((500-16-67.5)/83., (500-24-67.5)/83.), equivalentDiameter(9)},
+		// holes are subtracted whichever way they wind
+		{"reversed hole", RegionHulls(outer.Reverse(), left.Reverse()), 92,
+			geometry.PointXY((500-16)/92., (500-24)/92.), equivalentDiameter(8)},
+	}
+	const eps = 1e-9
+	for _, tt := range tests {
+		if got := tt.r.Area(); math.Abs(got-tt.area) > eps {
+			t.Errorf("%s: Area() = %v, want %v", tt.name, got, tt.area)
+		}
+		if got := tt.r.Centroid(); got.DistanceTo(tt.centroid) > eps {
+			t.Errorf("%s: Centroid() = %v, want %v", tt.name, got, tt.centroid)
+		}
+		if got := tt.r.HoleDiameter(); math.Abs(got-tt.holeDiameter) > eps {
+			t.Errorf("%s: HoleDiameter() = %v, want %v", tt.name, got, tt.holeDiameter)
+		}
+		if tt.r.Outer().IsCW() {
+			t.Errorf("%s: outer Hull winds clockwise", tt.name)
+		}
+		for _, h := range tt.r.Holes() {
+			if !h.IsCW() {
+				t.Errorf("%s: hole winds counterclockwise", tt.name)
+			}
+		}
+	}
+}
+
+func TestRegionContains(t *testing.T) {
+	r := RegionHulls(hullXY(0, 0, 10, 0, 10, 10, 0, 10), hullXY(2, 2, 8, 2, 8, 8, 2, 8))
+	tests := []struct {
+		p    geometry.Point
+		want bool
+	}{
+		{geometry.PointXY(1, 1), true},
+		{geometry.PointXY(5, 5), false},
+		{geometry.PointXY(9, 5), true},
+		{geometry.PointXY(11, 5), false},
+	}
+	for _, tt := range tests {
+		if got := r.Contains(tt.p); got != tt.want {
+			t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRegionsNesting(t *testing.T) {
+	// an island inside the hole of a ring is a Region of its own
+	hs := []Hull{
+		hullXY(4, 4, 6, 4, 6, 6, 4, 6),
+		hullXY(0, 0, 10, 0, 10, 10, 0, 10),
+		hullXY(2, 2, 8, 2, 8, 8, 2, 8),
+		hullXY(20, 0, 22, 0, 22, 2, 20, 2),
+	}
+	rs := Regions(hs)
+	if len(rs) != 3 {
+		t.Fatalf("got %d Regions, want 3", len(rs))
+	}
+	wants := []struct {
+		area  float64
+		holes int
+	}{{64, 1}, {4, 0}, {4, 0}}
+	for i, want := range wants {
+		if rs[i].Area() != want.area || len(rs[i].Holes()) != want.holes {
+			t.Errorf("Region %d: got area %v with %d holes, want %v with %d", i, rs[i].Area(), len(rs[i].Holes()), want.area, want.holes)
+		}
+	}
+
+	ring := HullRegions(grayRows(
+		"........",
+		".######.",
+		".######.",
+		".##..##.",
+		".##..##.",
+		".######.",
+		".######.",
+		"........",
+	), 0)
+	// the outlines run midway between white and black pixel centers and cut every corner by a quarter pixel²,
+	// so the 6×6 outer boundary encloses 35 pixels² and the 2×2 hole 3 pixels²
+	if len(ring) != 1 || len(ring[0].Holes()) != 1 {
+		t.Fatalf("HullRegions of a ring: got %d Regions", len(ring))
+	}
+	if a := ring[0].Area(); math.Abs(a-32) > 1e-9 {
+		t.Errorf("HullRegions of a ring: Area() = %v, want 32", a)
+	}
+}
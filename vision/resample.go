@@ -0,0 +1,383 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"screwSort/geometry"
+	"screwSort/utility"
+)
+
+// Interpolation selects how a resampled pixel is found from the pixels around its source location
+type Interpolation uint8
+
+const (
+	InterpolationNearest Interpolation = iota
+	InterpolationBilinear
+	InterpolationBicubic
+	// InterpolationArea averages the source pixels covered by the destination pixel when scaling,
+	// and falls back to InterpolationBilinear for other transforms
+	InterpolationArea
+)
+
+// Border describes the value of the pixels beyond the bounds of an image
+type Border struct {
+	mode borderMode
+	fill color.Color
+}
+
+type borderMode uint8
+
+const (
+	borderConstant borderMode = iota
+	borderReplicate
+	borderReflect
+	borderWrap
+)
+
+var (
+	// BorderReplicate repeats the nearest border pixel
+	BorderReplicate = Border{mode: borderReplicate}
+	// BorderReflect mirrors the image about its border
+	BorderReflect = Border{mode: borderReflect}
+	// BorderWrap tiles the image
+	BorderWrap = Border{mode: borderWrap}
+)
+
+// BorderConstant constructs a Border that fills the pixels beyond the bounds with the color
+func BorderConstant(c color.Color) Border {
+	return Border{borderConstant, c}
+}
+
+// Affine describes the transform x' = a·x + b·y + c, y' = d·x + e·y + f as [a, b, c, d, e, f]
+type Affine [6]float64
+
+// AffineIdentity constructs the Affine that leaves every Point in place
+func AffineIdentity() Affine {
+	return Affine{1, 0, 0, 0, 1, 0}
+}
+
+// AffineTranslate constructs the Affine that moves every Point by x and y
+func AffineTranslate(x, y float64) Affine {
+	return Affine{1, 0, x, 0, 1, y}
+}
+
+// AffineScale constructs the Affine that scales every Point about the origin by fx and fy
+func AffineScale(fx, fy float64) Affine {
+	return Affine{fx, 0, 0, 0, fy, 0}
+}
+
+// AffineRotate constructs the Affine that rotates every Point clockwise about q by the angle
+func AffineRotate(q geometry.Point, a float64) Affine {
+	s, c := math.Sincos(a)
+	return Affine{c, -s, q.X() - c*q.X() + s*q.Y(), s, c, q.Y() - s*q.X() - c*q.Y()}
+}
+
+// Then returns the Affine that applies the Affine followed by another
+func (t Affine) Then(o Affine) Affine {
+	return Affine{
+		o[0]*t[0] + o[1]*t[3], o[0]*t[1] + o[1]*t[4], o[0]*t[2] + o[1]*t[5] + o[2],
+		o[3]*t[0] + o[4]*t[3], o[3]*t[1] + o[4]*t[4], o[3]*t[2] + o[4]*t[5] + o[5],
+	}
+}
+
+// Inverse returns the Affine that undoes the Affine
+func (t Affine) Inverse() (Affine, error) {
+	det := t[0]*t[4] - t[1]*t[3]
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return Affine{}, ErrSingularTransform
+	}
+	return Affine{
+		t[4] / det, -t[1] / det, (t[1]*t[5] - t[4]*t[2]) / det,
+		-t[3] / det, t[0] / det, (t[3]*t[2] - t[0]*t[5]) / det,
+	}, nil
+}
+
+// Apply returns the Point transformed by the Affine
+func (t Affine) Apply(p geometry.Point) geometry.Point {
+	return geometry.PointXY(t[0]*p.X()+t[1]*p.Y()+t[2], t[3]*p.X()+t[4]*p.Y()+t[5])
+}
+
+// Scale returns the image resized by the factor with the Interpolation
+//
+// It returns ErrInvalidScale if the factor is not positive and finite or leaves the image without pixels
+func Scale(im *image.Gray, f float64, interp Interpolation, ws ...Workers) (*image.Gray, error) {
+	r, err := scaleRaster(grayRaster(im), f, interp, workersOf(ws))
+	if err != nil {
+		return nil, err
+	}
+	return r.gray(image.Rect(0, 0, r.dx, r.dy)), nil
+}
+
+// ScaleRgba returns the image resized by the factor with the Interpolation
+//
+// It returns ErrInvalidScale if the factor is not positive and finite or leaves the image without pixels
+func ScaleRgba(im *image.RGBA, f float64, interp Interpolation, ws ...Workers) (*image.RGBA, error) {
+	r, err := scaleRaster(rgbaRaster(im), f, interp, workersOf(ws))
+	if err != nil {
+		return nil, err
+	}
+	return r.rgba(image.Rect(0, 0, r.dx, r.dy)), nil
+}
+
+// Rotate returns the image rotated clockwise about q by the angle with the Interpolation,
+// keeping the bounds of the image
+//
+// It returns ErrSingularTransform if the angle is not finite
+func Rotate(im *image.Gray, q geometry.Point, a float64, interp Interpolation, border Border, ws ...Workers) (*image.Gray, error) {
+	return WarpAffine(im, AffineRotate(q, a), im.Rect, interp, border, ws...)
+}
+
+// RotateRgba returns the image rotated clockwise about q by the angle with the Interpolation,
+// keeping the bounds of the image
+//
+// It returns ErrSingularTransform if the angle is not finite
+func RotateRgba(im *image.RGBA, q geometry.Point, a float64, interp Interpolation, border Border, ws ...Workers) (*image.RGBA, error) {
+	return WarpAffineRgba(im, AffineRotate(q, a), im.Rect, interp, border, ws...)
+}
+
+// WarpAffine returns the image within the bounds after moving every point of the image by the Affine
+//
+// Pixels are sampled at their centers and the Border supplies the pixels beyond the bounds of the image
+func WarpAffine(im *image.Gray, t Affine, bounds image.Rectangle, interp Interpolation, border Border, ws ...Workers) (*image.Gray, error) {
+	r, err := warpRaster(grayRaster(im), im.Rect.Min, t, bounds, interp, border, workersOf(ws))
+	if err != nil {
+		return nil, err
+	}
+	return r.gray(bounds), nil
+}
+
+// WarpAffineRgba returns the image within the bounds after moving every point of the image by the Affine
+//
+// Pixels are sampled at their centers and the Border supplies the pixels beyond the bounds of the image
+func WarpAffineRgba(im *image.RGBA, t Affine, bounds image.Rectangle, interp Interpolation, border Border, ws ...Workers) (*image.RGBA, error) {
+	r, err := warpRaster(rgbaRaster(im), im.Rect.Min, t, bounds, interp, border, workersOf(ws))
+	if err != nil {
+		return nil, err
+	}
+	return r.rgba(bounds), nil
+}
+
+// raster describes the interleaved channel values of an image with its origin at 0
+type raster struct {
+	dx, dy, n int
+	vs        []float64
+}
+
+func grayRaster(im *image.Gray) raster {
+	return raster{im.Rect.Dx(), im.Rect.Dy(), 1, grayValues(im)}
+}
+
+// rgbaRaster keeps the premultiplied values so that transparent pixels do not bleed color
+func rgbaRaster(im *image.RGBA) raster {
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	vs := make([]float64, 4*dx*dy)
+	for y := 0; y < dy; y++ {
+		row := im.Pix[y*im.Stride : y*im.Stride+4*dx]
+		for i, v := range row {
+			vs[4*y*dx+i] = float64(v)
+		}
+	}
+	return raster{dx, dy, 4, vs}
+}
+
+func (r raster) gray(bounds image.Rectangle) *image.Gray {
+	out := image.NewGray(bounds)
+	for y := 0; y < r.dy; y++ {
+		for x := 0; x < r.dx; x++ {
+			out.Pix[y*out.Stride+x] = clampValue(r.vs[y*r.dx+x])
+		}
+	}
+	return out
+}
+
+func (r raster) rgba(bounds image.Rectangle) *image.RGBA {
+	out := image.NewRGBA(bounds)
+	for y := 0; y < r.dy; y++ {
+		for i := 0; i < 4*r.dx; i++ {
+			out.Pix[y*out.Stride+i] = clampValue(r.vs[4*y*r.dx+i])
+		}
+	}
+	// interpolation can overshoot the alpha of a premultiplied color
+	for i := 0; i < len(out.Pix); i += 4 {
+		a := out.Pix[i+3]
+		out.Pix[i], out.Pix[i+1], out.Pix[i+2] = utility.Min(out.Pix[i], a), utility.Min(out.Pix[i+1], a), utility.Min(out.Pix[i+2], a)
+	}
+	return out
+}
+
+// fillValues returns the channel values of the color in the layout of the raster
+func (r raster) fillValues(c color.Color) []float64 {
+	if c == nil {
+		return make([]float64, r.n)
+	}
+	if r.n == 1 {
+		return []float64{float64(color.GrayModel.Convert(c).(color.Gray).Y)}
+	}
+	v := color.RGBAModel.Convert(c).(color.RGBA)
+	return []float64{float64(v.R), float64(v.G), float64(v.B), float64(v.A)}
+}
+
+// index returns the index of the pixel in the raster after applying the Border, or -1 for the constant fill
+func (r raster) index(x, y int, border Border) int {
+	if x >= 0 && y >= 0 && x < r.dx && y < r.dy {
+		return y*r.dx + x
+	}
+	switch border.mode {
+	case borderReplicate:
+		x, y = clamp(x, 0, r.dx-1), clamp(y, 0, r.dy-1)
+	case borderReflect:
+		x, y = reflectIndex(x, r.dx), reflectIndex(y, r.dy)
+	case borderWrap:
+		x, y = (x%r.dx+r.dx)%r.dx, (y%r.dy+r.dy)%r.dy
+	default:
+		return -1
+	}
+	return y*r.dx + x
+}
+
+// reflectIndex mirrors the index into {0, ..., n-1} repeating the edge pixel
+func reflectIndex(i, n int) int {
+	i = (i%(2*n) + 2*n) % (2 * n)
+	if i >= n {
+		i = 2*n - 1 - i
+	}
+	return i
+}
+
+// sample adds the weighted values at the continuous location (u, v) in pixel coordinates to out
+func (r raster) sample(u, v float64, interp Interpolation, border Border, fill, out []float64) {
+	add := func(x, y int, wt float64) {
+		if wt == 0 {
+			return
+		}
+		if i := r.index(x, y, border); i >= 0 {
+			for k := 0; k < r.n; k++ {
+				out[k] += wt * r.vs[i*r.n+k]
+			}
+		} else {
+			for k := 0; k < r.n; k++ {
+				out[k] += wt * fill[k]
+			}
+		}
+	}
+	switch interp {
+	case InterpolationNearest:
+		add(int(math.Floor(u)), int(math.Floor(v)), 1)
+	case InterpolationBicubic:
+		x0, y0 := math.Floor(u-0.5), math.Floor(v-0.5)
+		var wx, wy [4]float64
+		for k := 0; k < 4; k++ {
+			wx[k], wy[k] = cubicWeight(u-0.5-(x0+float64(k-1))), cubicWeight(v-0.5-(y0+float64(k-1)))
+		}
+		for j := 0; j < 4; j++ {
+			for i := 0; i < 4; i++ {
+				add(int(x0)+i-1, int(y0)+j-1, wx[i]*wy[j])
+			}
+		}
+	default:
+		x0, y0 := math.Floor(u-0.5), math.Floor(v-0.5)
+		fx, fy := u-0.5-x0, v-0.5-y0
+		add(int(x0), int(y0), (1-fx)*(1-fy))
+		add(int(x0)+1, int(y0), fx*(1-fy))
+		add(int(x0), int(y0)+1, (1-fx)*fy)
+		add(int(x0)+1, int(y0)+1, fx*fy)
+	}
+}
+
+// cubicWeight returns the weight of the Keys cubic convolution kernel with a = -0.5 at the distance
+func cubicWeight(d float64) float64 {
+	d = math.Abs(d)
+	switch {
+	case d < 1:
+		return (1.5*d-2.5)*d*d + 1
+	case d < 2:
+		return ((-0.5*d+2.5)*d-4)*d + 2
+	}
+	return 0
+}
+
+// warpRaster samples the raster, whose origin is at min in image coordinates, at the source of every pixel
+// of the bounds under the Affine
+func warpRaster(src raster, min image.Point, t Affine, bounds image.Rectangle, interp Interpolation, border Border, ws Workers) (raster, error) {
+	inv, err := t.Inverse()
+	if err != nil {
+		return raster{}, err
+	}
+	dx, dy := bounds.Dx(), bounds.Dy()
+	out := raster{dx, dy, src.n, make([]float64, dx*dy*src.n)}
+	fill := src.fillValues(border.fill)
+	ws.parallelRows(dy, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < dx; x++ {
+				p := inv.Apply(geometry.PointImage(x+bounds.Min.X, y+bounds.Min.Y))
+				i := (y*dx + x) * src.n
+				src.sample(p.X()-float64(min.X), p.Y()-float64(min.Y), interp, border, fill, out.vs[i:i+src.n])
+			}
+		}
+	})
+	return out, nil
+}
+
+// scaleRaster resizes the raster by the factor, integrating over the source pixels for InterpolationArea
+func scaleRaster(src raster, f float64, interp Interpolation, ws Workers) (raster, error) {
+	if !(f > 0) || math.IsInf(f, 1) {
+		return raster{}, ErrInvalidScale
+	}
+	dx, dy := utility.IntRound(f*float64(src.dx)), utility.IntRound(f*float64(src.dy))
+	if dx < 1 || dy < 1 {
+		return raster{}, ErrInvalidScale
+	}
+	if interp != InterpolationArea {
+		t := AffineScale(float64(dx)/float64(src.dx), float64(dy)/float64(src.dy))
+		return warpRaster(src, image.Point{}, t, image.Rect(0, 0, dx, dy), interp, BorderReplicate, ws)
+	}
+
+	wxs, wys := areaWeights(src.dx, dx), areaWeights(src.dy, dy)
+	tmp := make([]float64, dx*src.dy*src.n)
+	for y := 0; y < src.dy; y++ {
+		for x, ws := range wxs {
+			for _, wt := range ws {
+				for k := 0; k < src.n; k++ {
+					tmp[(y*dx+x)*src.n+k] += wt.w * src.vs[(y*src.dx+wt.i)*src.n+k]
+				}
+			}
+		}
+	}
+	out := raster{dx, dy, src.n, make([]float64, dx*dy*src.n)}
+	for y, ws := range wys {
+		for _, wt := range ws {
+			for x := 0; x < dx; x++ {
+				for k := 0; k < src.n; k++ {
+					out.vs[(y*dx+x)*src.n+k] += wt.w * tmp[(wt.i*dx+x)*src.n+k]
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+type areaWeight struct {
+	i int
+	w float64
+}
+
+// areaWeights returns for every one of the m destination pixels the source pixels out of n that it covers
+// and the fraction of its width that each covers
+func areaWeights(n, m int) [][]areaWeight {
+	r := float64(n) / float64(m)
+	wss := make([][]areaWeight, m)
+	for j := range wss {
+		lo, hi := float64(j)*r, float64(j+1)*r
+		for i := int(math.Floor(lo)); float64(i) < hi && i < n; i++ {
+			if o := math.Min(hi, float64(i+1)) - math.Max(lo, float64(i)); o > 0 {
+				wss[j] = append(wss[j], areaWeight{i, o / r})
+			}
+		}
+	}
+	return wss
+}
+
+func clampValue(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
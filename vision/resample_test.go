@@ -0,0 +1,106 @@
+package vision
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"screwSort/geometry"
+	"testing"
+)
+
+func TestRotate(t *testing.T) {
+	const n = 7
+	im := image.NewGray(image.Rect(0, 0, n, n))
+	for i := range im.Pix {
+		im.Pix[i] = uint8(i * 5)
+	}
+	// rotating clockwise by a quarter turn moves pixel (x, y) to (n-1-y, x)
+	quarter := image.NewGray(im.Rect)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			quarter.Pix[x*quarter.Stride+n-1-y] = im.Pix[y*im.Stride+x]
+		}
+	}
+	q := geometry.PointXY(n/2., n/2.)
+	tests := []struct {
+		name string
+		a    float64
+		want *image.Gray
+		err  error
+	}{
+		{"no rotation", 0, im, nil},
+		{"quarter turn", math.Pi / 2, quarter, nil},
+		{"full turn", 2 * math.Pi, im, nil},
+		{"NaN angle", math.NaN(), nil, ErrSingularTransform},
+		{"infinite angle", math.Inf(1), nil, ErrSingularTransform},
+	}
+	for _, tt := range tests {
+		got, err := Rotate(im, q, tt.a, InterpolationNearest, BorderReplicate)
+		if err != tt.err {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err == nil && !bytes.Equal(got.Pix, tt.want.Pix) {
+			t.Errorf("%s: got %v, want %v", tt.name, got.Pix, tt.want.Pix)
+		}
+		rgba, err := RotateRgba(ToRgba(im), q, tt.a, InterpolationNearest, BorderReplicate)
+		if err != tt.err {
+			t.Errorf("%s: RotateRgba got error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err == nil && !bytes.Equal(ToGray(rgba).Pix, tt.want.Pix) {
+			t.Errorf("%s: RotateRgba differs from Rotate", tt.name)
+		}
+	}
+}
+
+func TestWarpAffineSingular(t *testing.T) {
+	im := image.NewGray(image.Rect(0, 0, 4, 4))
+	for _, tr := range []Affine{AffineScale(0, 1), AffineScale(1, math.NaN()), {1, 2, 0, 2, 4, 0}} {
+		if _, err := WarpAffine(im, tr, im.Rect, InterpolationBilinear, BorderReplicate); err != ErrSingularTransform {
+			t.Errorf("WarpAffine(%v): got %v, want ErrSingularTransform", tr, err)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	im := image.NewGray(image.Rect(0, 0, 6, 4))
+	for i := range im.Pix {
+		im.Pix[i] = uint8(i * 9)
+	}
+	for _, interp := range []Interpolation{InterpolationNearest, InterpolationBilinear, InterpolationBicubic, InterpolationArea} {
+		got, err := Scale(im, 2, interp)
+		if err != nil || got.Rect != image.Rect(0, 0, 12, 8) {
+			t.Errorf("Scale with Interpolation %v: got %v and error %v, want %v", interp, got.Rect, err, image.Rect(0, 0, 12, 8))
+		}
+		rgba, err := ScaleRgba(ToRgba(im), 0.5, interp)
+		if err != nil || rgba.Rect != image.Rect(0, 0, 3, 2) {
+			t.Errorf("ScaleRgba with Interpolation %v: got %v and error %v, want %v", interp, rgba.Rect, err, image.Rect(0, 0, 3, 2))
+		}
+	}
+	// a uniform image stays uniform under every Interpolation
+	uniform := image.NewGray(image.Rect(0, 0, 5, 5))
+	for i := range uniform.Pix {
+		uniform.Pix[i] = 77
+	}
+	for _, interp := range []Interpolation{InterpolationNearest, InterpolationBilinear, InterpolationBicubic, InterpolationArea} {
+		got, _ := Scale(uniform, 1.7, interp)
+		for _, v := range got.Pix {
+			if v != 77 {
+				t.Errorf("Scale of a uniform image with Interpolation %v: got %v, want 77", interp, v)
+				break
+			}
+		}
+	}
+
+	for _, f := range []float64{0, -0.5, math.NaN(), math.Inf(1), 0.01} {
+		for _, interp := range []Interpolation{InterpolationBilinear, InterpolationArea} {
+			if _, err := Scale(im, f, interp); err != ErrInvalidScale {
+				t.Errorf("Scale by %v: got %v, want ErrInvalidScale", f, err)
+			}
+			if _, err := ScaleRgba(ToRgba(im), f, interp); err != ErrInvalidScale {
+				t.Errorf("ScaleRgba by %v: got %v, want ErrInvalidScale", f, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package vision
+
+import (
+	"container/heap"
+	"math"
+	"screwSort/geometry"
+	"sort"
+)
+
+// SimplifyRDP returns the Hull reduced by the Ramer–Douglas–Peucker algorithm
+// so that every removed point is within epsilon of the simplified outline
+func (h Hull) SimplifyRDP(epsilon float64) Hull {
+	return h.derive(h.keep(h.simplifyRDP(epsilon, nil)))
+}
+
+// SimplifyVW returns the Hull reduced by the Visvalingam–Whyatt algorithm, which removes the points
+// that span the smallest triangles with their neighbors first, for as long as every removed point
+// stays within epsilon of the simplified outline
+func (h Hull) SimplifyVW(epsilon float64) Hull {
+	return h.derive(h.keep(h.simplifyVW(epsilon, nil)))
+}
+
+// SimplifyCorners returns the Hull reduced like SimplifyRDP but always keeping the Corners found
+// with support d and turning angle at least angle, so that the sharp corners of heads and nuts stay exact
+func (h Hull) SimplifyCorners(epsilon, d, angle float64) Hull {
+	return h.derive(h.keep(h.simplifyRDP(epsilon, h.Corners(d, angle))))
+}
+
+// Corners returns the indices of the points of the Hull where it turns by at least the angle, measured between
+// the chords to the points the distance d away along it on either side, keeping the sharpest point of each corner
+func (h Hull) Corners(d, angle float64) []int {
+	n := len(h.ps)
+	if n < 3 {
+		return nil
+	}
+	// cumulative arc length with the closing segment so that the points d away can be found by walking
+	ls := make([]float64, n+1)
+	for i := 0; i < n; i++ {
+		ls[i+1] = ls[i] + h.ps[i].DistanceTo(h.ps[(i+1)%n])
+	}
+	l := ls[n]
+	if l == 0 || 2*d >= l {
+		return nil
+	}
+	at := func(s float64) geometry.Point {
+		s = math.Mod(s+l, l)
+		i := sort.SearchFloat64s(ls, s)
+		if i == 0 {
+			return h.ps[0]
+		}
+		p, q := h.ps[i-1], h.ps[i%n]
+		f := 0.
+		if ls[i] > ls[i-1] {
+			f = (s - ls[i-1]) / (ls[i] - ls[i-1])
+		}
+		return geometry.PointXY(p.X()+f*(q.X()-p.X()), p.Y()+f*(q.Y()-p.Y()))
+	}
+	turns := make([]float64, n)
+	for i, p := range h.ps {
+		u, v := p.Subtract(at(ls[i]-d)), at(ls[i]+d).Subtract(p)
+		if u.R() > 0 && v.R() > 0 {
+			turns[i] = math.Abs(math.Atan2(u.Cross(v), u.Dot(v)))
+		}
+	}
+
+	// keep the maximum of every run of points turning by at least the angle
+	start := 0
+	for start < n && turns[start] >= angle {
+		start++
+	}
+	if start == n {
+		return nil
+	}
+	var is []int
+	best := -1
+	for k := 1; k <= n; k++ {
+		i := (start + k) % n
+		if turns[i] >= angle {
+			if best < 0 || turns[i] > turns[best] {
+				best = i
+			}
+		} else if best >= 0 {
+			is = append(is, best)
+			best = -1
+		}
+	}
+	sort.Ints(is)
+	return is
+}
+
+// keep returns the points of the Hull at the indices that are marked
+func (h Hull) keep(marks []bool) []geometry.Point {
+	var ps []geometry.Point
+	for i, m := range marks {
+		if m {
+			ps = append(ps, h.ps[i])
+		}
+	}
+	return ps
+}
+
+// spanWithin returns whether every point strictly between the indices i and j going forward around the Hull
+// is within epsilon of the segment between them
+func (h Hull) spanWithin(i, j int, epsilon float64) bool {
+	n := len(h.ps)
+	s := geometry.SegmentPQ(h.ps[i], h.ps[j])
+	for k := (i + 1) % n; k != j; k = (k + 1) % n {
+		if s.DistanceTo(h.ps[k]) > epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+// anchors returns the sorted indices that split the closed Hull into open chains, which are the given indices
+// or, if there are fewer than two, those extended with the points farthest from them
+func (h Hull) anchors(is []int) []int {
+	n := len(h.ps)
+	as := append([]int(nil), is...)
+	if len(as) == 0 {
+		as = append(as, 0)
+	}
+	for len(as) < 2 {
+		f, df := as[0], 0.
+		for i, p := range h.ps {
+			if d := p.DistanceTo(h.ps[as[0]]); d > df {
+				f, df = i, d
+			}
+		}
+		if f == as[0] {
+			f = (as[0] + n/2) % n
+		}
+		as = append(as, f)
+	}
+	sort.Ints(as)
+	return as
+}
+
+// simplifyRDP marks the points kept by the Ramer–Douglas–Peucker algorithm run on each chain between anchors
+func (h Hull) simplifyRDP(epsilon float64, is []int) []bool {
+	n := len(h.ps)
+	marks := make([]bool, n)
+	if n <= 3 {
+		for i := range marks {
+			marks[i] = true
+		}
+		return marks
+	}
+	as := h.anchors(is)
+	type chain struct{ i, j int }
+	var stack []chain
+	for k, a := range as {
+		marks[a] = true
+		stack = append(stack, chain{a, as[(k+1)%len(as)]})
+	}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		s := geometry.SegmentPQ(h.ps[c.i], h.ps[c.j])
+		f, df := -1, epsilon
+		for k := (c.i + 1) % n; k != c.j; k = (k + 1) % n {
+			if d := s.DistanceTo(h.ps[k]); d > df {
+				f, df = k, d
+			}
+		}
+		if f >= 0 {
+			marks[f] = true
+			stack = append(stack, chain{c.i, f}, chain{f, c.j})
+		}
+	}
+	return marks
+}
+
+type vwItem struct {
+	area    float64
+	i       int
+	version int
+}
+
+type vwQueue []vwItem
+
+func (q vwQueue) Len() int { return len(q) }
+func (q vwQueue) Less(i, j int) bool {
+	return q[i].area < q[j].area || q[i].area == q[j].area && q[i].i < q[j].i
+}
+func (q vwQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *vwQueue) Push(x interface{}) { *q = append(*q, x.(vwItem)) }
+func (q *vwQueue) Pop() interface{} {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
+
+// simplifyVW marks the points kept by the Visvalingam–Whyatt algorithm, never removing the anchors
+// or a point whose removal would leave a removed point farther than epsilon from the outline
+func (h Hull) simplifyVW(epsilon float64, is []int) []bool {
+	n := len(h.ps)
+	marks := make([]bool, n)
+	for i := range marks {
+		marks[i] = true
+	}
+	if n <= 3 {
+		return marks
+	}
+	fixed := make([]bool, n)
+	for _, a := range is {
+		fixed[a] = true
+	}
+	prev, next, versions := make([]int, n), make([]int, n), make([]int, n)
+	for i := range h.ps {
+		prev[i], next[i] = (i+n-1)%n, (i+1)%n
+	}
+	area := func(i int) float64 {
+		return math.Abs(h.ps[prev[i]].OrientationOf(h.ps[i], h.ps[next[i]])) / 2
+	}
+	q := &vwQueue{}
+	for i := range h.ps {
+		if !fixed[i] {
+			heap.Push(q, vwItem{area(i), i, 0})
+		}
+	}
+	for m := n; q.Len() > 0 && m > 3; {
+		it := heap.Pop(q).(vwItem)
+		i := it.i
+		if it.version != versions[i] || !marks[i] || !h.spanWithin(prev[i], next[i], epsilon) {
+			continue
+		}
+		marks[i] = false
+		m--
+		p, r := prev[i], next[i]
+		next[p], prev[r] = r, p
+		for _, k := range []int{p, r} {
+			if !fixed[k] {
+				versions[k]++
+				heap.Push(q, vwItem{area(k), k, versions[k]})
+			}
+		}
+	}
+	return marks
+}
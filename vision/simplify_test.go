@@ -0,0 +1,133 @@
+package vision
+
+import (
+	"math"
+	"screwSort/geometry"
+	"testing"
+)
+
+// noisyRectangle returns the Hull around the w×h rectangle sampled every unit with deterministic noise
+// of at most the amplitude perpendicular to its sides and the indices of its corners
+func noisyRectangle(w, h int, amplitude float64) (Hull, []int) {
+	var ps []geometry.Point
+	var corners []int
+	side := func(x0, y0, dx, dy, n int) {
+		corners = append(corners, len(ps))
+		for k := 0; k < n; k++ {
+			// the corner itself is exact and the noise moves the other points across the side
+			e := 0.
+			if k > 0 {
+				e = amplitude * math.Sin(1.7*float64(len(ps)))
+			}
+			x, y := float64(x0+k*dx), float64(y0+k*dy)
+			ps = append(ps, geometry.PointXY(x-e*float64(dy), y+e*float64(dx)))
+		}
+	}
+	side(0, 0, 1, 0, w)
+	side(w, 0, 0, 1, h)
+	side(w, h, -1, 0, w)
+	side(0, h, 0, -1, h)
+	return HullPs(ps), corners
+}
+
+// deviation returns the largest distance of a point of the Hull from the closed outline through the points
+func deviation(h Hull, ps []geometry.Point) float64 {
+	dMax := 0.
+	for _, p := range h.Ps() {
+		d := math.Inf(1)
+		for i, q := range ps {
+			d = math.Min(d, geometry.SegmentPQ(q, ps[(i+1)%len(ps)]).DistanceTo(p))
+		}
+		dMax = math.Max(dMax, d)
+	}
+	return dMax
+}
+
+// isSubsequence returns whether the points appear in the same cyclic order among those of the Hull
+func isSubsequence(h Hull, ps []geometry.Point) bool {
+	if len(ps) == 0 {
+		return true
+	}
+	qs := h.Ps()
+	start := -1
+	for i, q := range qs {
+		if q == ps[0] {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return false
+	}
+	k := 0
+	for i := 0; i < len(qs) && k < len(ps); i++ {
+		if qs[(start+i)%len(qs)] == ps[k] {
+			k++
+		}
+	}
+	return k == len(ps)
+}
+
+func TestSimplifyDeviation(t *testing.T) {
+	rect, _ := noisyRectangle(60, 30, 0.3)
+	circle := polygonDisk(40, 40, 25, 400).Map(func(p geometry.Point) geometry.Point {
+		return p.Translate(0.2*math.Sin(7*p.Y()), 0.2*math.Cos(5*p.X()))
+	})
+	for name, h := range map[string]Hull{"rectangle": rect, "circle": circle} {
+		for _, epsilon := range []float64{0.1, 0.5, 1, 3} {
+			for method, s := range map[string]Hull{
+				"SimplifyRDP":     h.SimplifyRDP(epsilon),
+				"SimplifyVW":      h.SimplifyVW(epsilon),
+				"SimplifyCorners": h.SimplifyCorners(epsilon, 4, math.Pi/4),
+			} {
+				ps := s.Ps()
+				if len(ps) < 3 || len(ps) > len(h.Ps()) {
+					t.Errorf("%s of %s with epsilon %v: got %d of %d points", method, name, epsilon, len(ps), len(h.Ps()))
+					continue
+				}
+				if d := deviation(h, ps); d > epsilon+1e-9 {
+					t.Errorf("%s of %s with epsilon %v: a removed point is %v from the outline", method, name, epsilon, d)
+				}
+				if !isSubsequence(h, ps) {
+					t.Errorf("%s of %s with epsilon %v: points are not kept in order", method, name, epsilon)
+				}
+				if s.IsCW() != h.IsCW() {
+					t.Errorf("%s of %s with epsilon %v: winding changed", method, name, epsilon)
+				}
+			}
+		}
+	}
+}
+
+func TestSimplifyKeepsCorners(t *testing.T) {
+	h, corners := noisyRectangle(60, 30, 0.3)
+	is := h.Corners(4, math.Pi/4)
+	if len(is) != len(corners) {
+		t.Fatalf("Corners = %v, want %v", is, corners)
+	}
+	for k, i := range is {
+		if i != corners[k] {
+			t.Errorf("Corners = %v, want %v", is, corners)
+			break
+		}
+	}
+	var cs []geometry.Point
+	for _, i := range corners {
+		cs = append(cs, h.Ps()[i])
+	}
+	for method, s := range map[string]Hull{
+		"SimplifyRDP":     h.SimplifyRDP(1),
+		"SimplifyVW":      h.SimplifyVW(1),
+		"SimplifyCorners": h.SimplifyCorners(1, 4, math.Pi/4),
+	} {
+		// the noise is within epsilon, so the rectangle reduces to its four corners
+		if len(s.Ps()) != 4 || !isSubsequence(s, cs) {
+			t.Errorf("%s: got %v, want the corners %v", method, s.Ps(), cs)
+		}
+	}
+
+	// the corners survive even an epsilon that would otherwise cut them
+	if s := h.SimplifyCorners(20, 4, math.Pi/4); !isSubsequence(s, cs) {
+		t.Errorf("SimplifyCorners with a large epsilon dropped a corner: %v", s.Ps())
+	}
+}
@@ -0,0 +1,206 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"screwSort/utility"
+)
+
+// GaussianBlur returns the image convolved with a separable Gaussian kernel of standard deviation sigma
+//
+// Pixels beyond the border take the value of the nearest border pixel,
+// and ErrInvalidSigma is returned if sigma is not positive
+func GaussianBlur(im *image.Gray, sigma float64, ws ...Workers) (*image.Gray, error) {
+	if !(sigma > 0) {
+		return nil, ErrInvalidSigma
+	}
+	return roundValues(im.Rect, gaussianValues(im, sigma, workersOf(ws))), nil
+}
+
+// BoxBlur returns the image averaged over (2r+1)×(2r+1) windows using a separable box kernel
+//
+// Pixels beyond the border take the value of the nearest border pixel,
+// and ErrInvalidRadius is returned if r is negative
+func BoxBlur(im *image.Gray, r int, ws ...Workers) (*image.Gray, error) {
+	if r < 0 {
+		return nil, ErrInvalidRadius
+	}
+	ks := make([]float64, 2*r+1)
+	for i := range ks {
+		ks[i] = 1 / float64(len(ks))
+	}
+	return roundValues(im.Rect, separable(grayValues(im), im.Rect.Dx(), im.Rect.Dy(), ks, workersOf(ws))), nil
+}
+
+// MedianBlur returns the image with each pixel replaced by the median of its (2r+1)×(2r+1) window
+// using a sliding histogram
+//
+// Pixels beyond the border take the value of the nearest border pixel,
+// and ErrInvalidRadius is returned if r is negative
+func MedianBlur(im *image.Gray, r int, ws ...Workers) (*image.Gray, error) {
+	if r < 0 {
+		return nil, ErrInvalidRadius
+	}
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	out := image.NewGray(im.Rect)
+	at := func(x, y int) uint8 {
+		x, y = clamp(x, 0, dx-1), clamp(y, 0, dy-1)
+		return im.Pix[y*im.Stride+x]
+	}
+	half := (2*r+1)*(2*r+1)/2 + 1
+	workersOf(ws).parallelRows(dy, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			var hist [256]int
+			for j := -r; j <= r; j++ {
+				for i := -r; i <= r; i++ {
+					hist[at(i, y+j)]++
+				}
+			}
+			for x := 0; x < dx; x++ {
+				if x > 0 {
+					for j := -r; j <= r; j++ {
+						hist[at(x-r-1, y+j)]--
+						hist[at(x+r, y+j)]++
+					}
+				}
+				n, v := 0, 0
+				for ; v < 255; v++ {
+					if n += hist[v]; n >= half {
+						break
+					}
+				}
+				out.Pix[y*out.Stride+x] = uint8(v)
+			}
+		}
+	})
+	return out, nil
+}
+
+// BilateralFilter returns the image smoothed by weights that fall off with both the distance from the pixel,
+// with standard deviation sigmaSpace, and the difference in value, with standard deviation sigmaRange,
+// so that edges are preserved
+//
+// It returns ErrInvalidSigma if either standard deviation is not positive
+func BilateralFilter(im *image.Gray, sigmaSpace, sigmaRange float64, ws ...Workers) (*image.Gray, error) {
+	if !(sigmaSpace > 0) || !(sigmaRange > 0) {
+		return nil, ErrInvalidSigma
+	}
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	r := int(math.Ceil(3 * sigmaSpace))
+	ds := make([]float64, (2*r+1)*(2*r+1))
+	for j := -r; j <= r; j++ {
+		for i := -r; i <= r; i++ {
+			ds[(j+r)*(2*r+1)+i+r] = math.Exp(-float64(i*i+j*j) / (2 * sigmaSpace * sigmaSpace))
+		}
+	}
+	var rs [256]float64
+	for d := range rs {
+		rs[d] = math.Exp(-float64(d*d) / (2 * sigmaRange * sigmaRange))
+	}
+
+	out := image.NewGray(im.Rect)
+	workersOf(ws).parallelRows(dy, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < dx; x++ {
+				v := im.Pix[y*im.Stride+x]
+				var s, sw float64
+				for j := utility.Max(-r, -y); j <= utility.Min(r, dy-1-y); j++ {
+					for i := utility.Max(-r, -x); i <= utility.Min(r, dx-1-x); i++ {
+						u := im.Pix[(y+j)*im.Stride+x+i]
+						wt := ds[(j+r)*(2*r+1)+i+r] * rs[utility.AbsInt(int(u)-int(v))]
+						s += wt * float64(u)
+						sw += wt
+					}
+				}
+				out.Pix[y*out.Stride+x] = uint8(math.Round(s / sw))
+			}
+		}
+	})
+	return out, nil
+}
+
+// gaussianValues returns the unquantized values of the image convolved with a Gaussian of standard deviation sigma
+func gaussianValues(im *image.Gray, sigma float64, ws Workers) []float64 {
+	vs := grayValues(im)
+	if sigma <= 0 {
+		return vs
+	}
+	return separable(vs, im.Rect.Dx(), im.Rect.Dy(), gaussianKernel(sigma), ws)
+}
+
+// gaussianKernel returns the normalized Gaussian kernel of standard deviation sigma truncated at 3 sigma
+func gaussianKernel(sigma float64) []float64 {
+	r := int(math.Ceil(3 * sigma))
+	ks := make([]float64, 2*r+1)
+	s := 0.
+	for i := range ks {
+		d := float64(i - r)
+		ks[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		s += ks[i]
+	}
+	for i := range ks {
+		ks[i] /= s
+	}
+	return ks
+}
+
+// separable convolves the dx×dy values with the odd-length kernel along x then along y,
+// replicating the border values
+func separable(vs []float64, dx, dy int, ks []float64, ws Workers) []float64 {
+	r := len(ks) / 2
+	tmp, out := make([]float64, len(vs)), make([]float64, len(vs))
+	ws.parallelRows(dy, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row := vs[y*dx : (y+1)*dx]
+			for x := 0; x < dx; x++ {
+				s := 0.
+				for i, k := range ks {
+					s += k * row[clamp(x+i-r, 0, dx-1)]
+				}
+				tmp[y*dx+x] = s
+			}
+		}
+	})
+	ws.parallelRows(dy, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < dx; x++ {
+				s := 0.
+				for i, k := range ks {
+					s += k * tmp[clamp(y+i-r, 0, dy-1)*dx+x]
+				}
+				out[y*dx+x] = s
+			}
+		}
+	})
+	return out
+}
+
+func grayValues(im *image.Gray) []float64 {
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	vs := make([]float64, dx*dy)
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			vs[y*dx+x] = float64(im.Pix[y*im.Stride+x])
+		}
+	}
+	return vs
+}
+
+func roundValues(rect image.Rectangle, vs []float64) *image.Gray {
+	out := image.NewGray(rect)
+	dx := rect.Dx()
+	for i, v := range vs {
+		out.Pix[(i/dx)*out.Stride+i%dx] = clampValue(v)
+	}
+	return out
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package vision
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"testing"
+)
+
+func TestSmoothInvalid(t *testing.T) {
+	im := parallelImage()
+	tests := []struct {
+		name string
+		f    func() (*image.Gray, error)
+		want error
+	}{
+		{"BoxBlur with r = 0", func() (*image.Gray, error) { return BoxBlur(im, 0) }, nil},
+		{"BoxBlur with r < 0", func() (*image.Gray, error) { return BoxBlur(im, -1) }, ErrInvalidRadius},
+		{"MedianBlur with r = 0", func() (*image.Gray, error) { return MedianBlur(im, 0) }, nil},
+		{"MedianBlur with r < 0", func() (*image.Gray, error) { return MedianBlur(im, -3) }, ErrInvalidRadius},
+		{"GaussianBlur", func() (*image.Gray, error) { return GaussianBlur(im, 1.5) }, nil},
+		{"GaussianBlur with sigma = 0", func() (*image.Gray, error) { return GaussianBlur(im, 0) }, ErrInvalidSigma},
+		{"GaussianBlur with sigma < 0", func() (*image.Gray, error) { return GaussianBlur(im, -1) }, ErrInvalidSigma},
+		{"GaussianBlur with NaN sigma", func() (*image.Gray, error) { return GaussianBlur(im, math.NaN()) }, ErrInvalidSigma},
+		{"BilateralFilter", func() (*image.Gray, error) { return BilateralFilter(im, 1, 20) }, nil},
+		{"BilateralFilter with sigmaSpace = 0", func() (*image.Gray, error) { return BilateralFilter(im, 0, 20) }, ErrInvalidSigma},
+		{"BilateralFilter with sigmaSpace < 0", func() (*image.Gray, error) { return BilateralFilter(im, -1, 20) }, ErrInvalidSigma},
+		{"BilateralFilter with sigmaRange = 0", func() (*image.Gray, error) { return BilateralFilter(im, 1, 0) }, ErrInvalidSigma},
+		{"BilateralFilter with NaN sigmaRange", func() (*image.Gray, error) { return BilateralFilter(im, 1, math.NaN()) }, ErrInvalidSigma},
+	}
+	for _, tt := range tests {
+		if _, err := tt.f(); err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSmoothIdentity(t *testing.T) {
+	im := parallelImage()
+	box, err := BoxBlur(im, 0)
+	if err != nil || !bytes.Equal(box.Pix, im.Pix) {
+		t.Errorf("BoxBlur with r = 0 changed the image: %v", err)
+	}
+	median, err := MedianBlur(im, 0)
+	if err != nil || !bytes.Equal(median.Pix, im.Pix) {
+		t.Errorf("MedianBlur with r = 0 changed the image: %v", err)
+	}
+	flat := image.NewGray(image.Rect(0, 0, 9, 9))
+	for i := range flat.Pix {
+		flat.Pix[i] = 77
+	}
+	for name, f := range map[string]func() (*image.Gray, error){
+		"GaussianBlur":    func() (*image.Gray, error) { return GaussianBlur(flat, 1.5) },
+		"BoxBlur":         func() (*image.Gray, error) { return BoxBlur(flat, 2) },
+		"MedianBlur":      func() (*image.Gray, error) { return MedianBlur(flat, 2) },
+		"BilateralFilter": func() (*image.Gray, error) { return BilateralFilter(flat, 1.5, 10) },
+	} {
+		got, err := f()
+		if err != nil || !bytes.Equal(got.Pix, flat.Pix) {
+			t.Errorf("%s changed a flat image: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package vision
+
+import (
+	"container/heap"
+	"image"
+	"math"
+	"screwSort/geometry"
+	"screwSort/utility"
+)
+
+// DistanceTransform returns the Surface of the exact Euclidean distance from every white pixel of the binary image
+// to the nearest black pixel, with black pixels at distance 0
+//
+// Pixels outside the image are not considered black
+func DistanceTransform(im *image.Gray) Surface {
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	vs := make([]float64, dx*dy)
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			if im.Pix[y*im.Stride+x] != b {
+				vs[y*dx+x] = math.Inf(1)
+			}
+		}
+	}
+
+	n := utility.Max(dx, dy)
+	f, d := make([]float64, n), make([]float64, n)
+	vz, zs := make([]int, n), make([]float64, n+1)
+	for x := 0; x < dx; x++ {
+		for y := 0; y < dy; y++ {
+			f[y] = vs[y*dx+x]
+		}
+		squaredDistance1D(f[:dy], d[:dy], vz, zs)
+		for y := 0; y < dy; y++ {
+			vs[y*dx+x] = d[y]
+		}
+	}
+	for y := 0; y < dy; y++ {
+		copy(f, vs[y*dx:(y+1)*dx])
+		squaredDistance1D(f[:dx], d[:dx], vz, zs)
+		for x := 0; x < dx; x++ {
+			vs[y*dx+x] = math.Sqrt(d[x])
+		}
+	}
+	return Surface{im.Rect, vs}
+}
+
+// squaredDistance1D computes the lower envelope of the parabolas rooted at f into d
+// as described by Felzenszwalb and Huttenlocher
+func squaredDistance1D(f, d []float64, v []int, z []float64) {
+	n := len(f)
+	k := -1
+	for q := 0; q < n; q++ {
+		if math.IsInf(f[q], 1) {
+			continue
+		}
+		for k >= 0 {
+			s := ((f[q] + float64(q*q)) - (f[v[k]] + float64(v[k]*v[k]))) / float64(2*(q-v[k]))
+			if s > z[k] {
+				k++
+				v[k], z[k], z[k+1] = q, s, math.Inf(1)
+				break
+			}
+			k--
+		}
+		if k < 0 {
+			k = 0
+			v[0], z[0], z[1] = q, math.Inf(-1), math.Inf(1)
+		}
+	}
+	if k < 0 {
+		for q := range d {
+			d[q] = math.Inf(1)
+		}
+		return
+	}
+	j := 0
+	for q := 0; q < n; q++ {
+		for z[j+1] < float64(q) {
+			j++
+		}
+		d[q] = float64((q-v[j])*(q-v[j])) + f[v[j]]
+	}
+}
+
+// Markers returns the Labels of the cores of the white regions of the binary image, where a core is a connected set
+// of pixels whose distance to the background is at least the fraction alpha of the largest distance in its region
+//
+// Touching objects joined by a narrow neck get separate cores.
+// It returns ErrBoundsMismatch if the distance Surface was not made for the bounds of the image
+func Markers(im *image.Gray, dist Surface, alpha float64) (Labels, error) {
+	if dist.rect != im.Rect {
+		return Labels{}, ErrBoundsMismatch
+	}
+	return markers(im, dist, alpha), nil
+}
+
+func markers(im *image.Gray, dist Surface, alpha float64) Labels {
+	ls, cs := Label(im, Connectivity8)
+	dMax := make([]float64, len(cs)+1)
+	for i, l := range ls.ls {
+		dMax[l] = math.Max(dMax[l], dist.vs[i])
+	}
+	cores := image.NewGray(im.Rect)
+	for i, l := range ls.ls {
+		if l != 0 && dist.vs[i] >= alpha*dMax[l] {
+			cores.Pix[(i/im.Rect.Dx())*cores.Stride+i%im.Rect.Dx()] = w
+		}
+	}
+	ms, _ := Label(cores, Connectivity8)
+	return ms
+}
+
+// Watershed floods the white pixels of the binary image from the labeled markers in order of decreasing
+// elevation of the Surface and returns the Labels of the catchment basins
+//
+// White pixels that cannot be reached from any marker remain unlabeled.
+// It returns ErrBoundsMismatch if the Surface or the markers were not made for the bounds of the image
+func Watershed(im *image.Gray, elevation Surface, markers Labels) (Labels, []Component, error) {
+	if elevation.rect != im.Rect || markers.rect != im.Rect {
+		return Labels{}, nil, ErrBoundsMismatch
+	}
+	ls, cs := watershed(im, elevation, markers)
+	return ls, cs, nil
+}
+
+func watershed(im *image.Gray, elevation Surface, markers Labels) (Labels, []Component) {
+	dx, dy := im.Rect.Dx(), im.Rect.Dy()
+	ls := make([]int, dx*dy)
+	q := &floodQueue{}
+	n := 0
+	push := func(i int) {
+		heap.Push(q, floodItem{elevation.vs[i], q.order, i})
+		q.order++
+	}
+	for i, l := range markers.ls {
+		if l != 0 && im.Pix[(i/dx)*im.Stride+i%dx] != b {
+			ls[i] = l
+			n = utility.Max(n, l)
+			push(i)
+		}
+	}
+
+	for q.Len() > 0 {
+		it := heap.Pop(q).(floodItem)
+		x, y := it.i%dx, it.i/dx
+		for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := x+d[0], y+d[1]
+			if nx < 0 || ny < 0 || nx >= dx || ny >= dy {
+				continue
+			}
+			j := ny*dx + nx
+			if ls[j] == 0 && im.Pix[ny*im.Stride+nx] != b {
+				ls[j] = ls[it.i]
+				push(j)
+			}
+		}
+	}
+	return Labels{im.Rect, ls}, components(im.Rect, ls, n)
+}
+
+// SeparateTouching splits the touching objects of the binary image with a distance transform and a marker-based
+// watershed and returns the Labels, the Components, and whether each Component still looks like a cluster
+// because its solidity is below solidityMin
+func SeparateTouching(im *image.Gray, alpha, solidityMin float64) (Labels, []Component, []bool) {
+	dist := DistanceTransform(im)
+	ls, cs := watershed(im, dist, markers(im, dist, alpha))
+	clusters := make([]bool, len(cs))
+	for i, c := range cs {
+		clusters[i] = c.area > 0 && c.Solidity() < solidityMin
+	}
+	return ls, cs, clusters
+}
+
+// Solidity returns the ratio of the area of the Component to the area of its convex hull
+func (c Component) Solidity() float64 {
+	if c.area == 0 {
+		return 0
+	}
+	// the outer corners of the first and last pixel of each row span the convex hull
+	rows := make(map[int][2]int)
+	for _, p := range c.ps {
+		r, e := rows[p.Y]
+		if !e {
+			r = [2]int{p.X, p.X}
+		}
+		r[0], r[1] = utility.Min(r[0], p.X), utility.Max(r[1], p.X)
+		rows[p.Y] = r
+	}
+	var ps []geometry.Point
+	for y, r := range rows {
+		ps = append(ps,
+			geometry.PointXY(float64(r[0]), float64(y)), geometry.PointXY(float64(r[0]), float64(y+1)),
+			geometry.PointXY(float64(r[1]+1), float64(y)), geometry.PointXY(float64(r[1]+1), float64(y+1)),
+		)
+	}
+	h, err := HullPs(ps).Convex()
+	if err != nil || h.Area() == 0 {
+		return 1
+	}
+	return math.Min(float64(c.area)/h.Area(), 1)
+}
+
+// ComponentHulls traces the outline of every labeled Component separately so that touching Components
+// get their own Hulls, and returns those enclosing at least aMin pixels²
+//
+// The Hulls of Components touching the border of the Labels are marked as truncated
+func ComponentHulls(ls Labels, cs []Component, aMin float64) []Hull {
+	var hs []Hull
+	for _, c := range cs {
+		if c.area == 0 {
+			continue
+		}
+		r := c.bounds.Inset(-1)
+		im := image.NewGray(image.Rect(0, 0, r.Dx(), r.Dy()))
+		for _, p := range c.ps {
+			im.Pix[(p.Y-r.Min.Y)*im.Stride+p.X-r.Min.X] = w
+		}
+		truncated := c.bounds.Min.X == ls.rect.Min.X || c.bounds.Min.Y == ls.rect.Min.Y ||
+			c.bounds.Max.X == ls.rect.Max.X || c.bounds.Max.Y == ls.rect.Max.Y
+		for _, h := range Hulls(im, aMin) {
+			h = h.Translate(float64(r.Min.X), float64(r.Min.Y))
+			h.truncated = truncated
+			hs = append(hs, h)
+		}
+	}
+	return hs
+}
+
+type floodItem struct {
+	v     float64
+	order int
+	i     int
+}
+
+// floodQueue pops the highest elevation first and breaks ties in insertion order
+type floodQueue struct {
+	items []floodItem
+	order int
+}
+
+func (q floodQueue) Len() int { return len(q.items) }
+func (q floodQueue) Less(i, j int) bool {
+	p, r := q.items[i], q.items[j]
+	return p.v > r.v || p.v == r.v && p.order < r.order
+}
+func (q floodQueue) Swap(i, j int)       { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *floodQueue) Push(x interface{}) { q.items = append(q.items, x.(floodItem)) }
+func (q *floodQueue) Pop() interface{} {
+	n := len(q.items)
+	it := q.items[n-1]
+	q.items = q.items[:n-1]
+	return it
+}
@@ -0,0 +1,174 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// distanceBruteForce returns the Euclidean distance from the pixel to the nearest black pixel of the image
+func distanceBruteForce(im *image.Gray, x, y int) float64 {
+	d := math.Inf(1)
+	for j := 0; j < im.Rect.Dy(); j++ {
+		for i := 0; i < im.Rect.Dx(); i++ {
+			if im.Pix[j*im.Stride+i] == b {
+				d = math.Min(d, math.Hypot(float64(i-x), float64(j-y)))
+			}
+		}
+	}
+	return d
+}
+
+// diskImage returns a binary image with white disks of the radii centered at the points
+func diskImage(dx, dy int, disks ...[3]float64) *image.Gray {
+	im := image.NewGray(image.Rect(0, 0, dx, dy))
+	for y := 0; y < dy; y++ {
+		for x := 0; x < dx; x++ {
+			for _, d := range disks {
+				if math.Hypot(float64(x)+0.5-d[0], float64(y)+0.5-d[1]) <= d[2] {
+					im.Pix[y*im.Stride+x] = w
+				}
+			}
+		}
+	}
+	return im
+}
+
+// invert returns the image with every value v replaced by 255-v
+func invert(im *image.Gray) *image.Gray {
+	out := image.NewGray(im.Rect)
+	for i, v := range im.Pix {
+		out.Pix[i] = 255 - v
+	}
+	return out
+}
+
+func TestDistanceTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		im   *image.Gray
+	}{
+		{"single black pixel", invert(grayRows(
+			".......",
+			".......",
+			"...#...",
+			".......",
+		))},
+		{"block", grayRows(
+			"..........",
+			".########.",
+			".########.",
+			".########.",
+			"..........",
+		)},
+		{"overlapping disks", diskImage(40, 25, [3]float64{12, 12, 9}, [3]float64{25, 13, 8})},
+		{"scattered", grayRows(
+			"#..##.#..",
+			"..#...##.",
+			"##...#...",
+			"#...#..##",
+		)},
+	}
+	for _, tt := range tests {
+		s := DistanceTransform(tt.im)
+		for y := 0; y < tt.im.Rect.Dy(); y++ {
+			for x := 0; x < tt.im.Rect.Dx(); x++ {
+				if got, want := s.At(x, y), distanceBruteForce(tt.im, x, y); math.Abs(got-want) > 1e-9 {
+					t.Errorf("%s: distance at (%d, %d) = %v, want %v", tt.name, x, y, got, want)
+				}
+			}
+		}
+	}
+
+	// without black pixels every distance is infinite
+	white := invert(image.NewGray(image.Rect(0, 0, 4, 3)))
+	s := DistanceTransform(white)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			if !math.IsInf(s.At(x, y), 1) {
+				t.Errorf("all white: distance at (%d, %d) = %v, want +Inf", x, y, s.At(x, y))
+			}
+		}
+	}
+}
+
+func TestSeparateTouching(t *testing.T) {
+	tests := []struct {
+		name  string
+		im    *image.Gray
+		parts int
+	}{
+		{"one disk", diskImage(30, 30, [3]float64{15, 15, 10}), 1},
+		{"two touching disks", diskImage(50, 30, [3]float64{14, 15, 10}, [3]float64{32, 15, 10}), 2},
+		{"two separate disks", diskImage(60, 30, [3]float64{12, 15, 8}, [3]float64{45, 15, 8}), 2},
+	}
+	for _, tt := range tests {
+		ls, cs, clusters := SeparateTouching(tt.im, 0.7, 0.9)
+		if len(cs) != tt.parts {
+			t.Errorf("%s: got %d Components, want %d", tt.name, len(cs), tt.parts)
+			continue
+		}
+		area := 0
+		for i, c := range cs {
+			area += c.Area()
+			if clusters[i] {
+				t.Errorf("%s: Component %d with solidity %v is marked as a cluster", tt.name, i, c.Solidity())
+			}
+		}
+		// every white pixel is reached from a marker
+		white := 0
+		for _, v := range tt.im.Pix {
+			if v != b {
+				white++
+			}
+		}
+		if area != white || ls.Bounds() != tt.im.Rect {
+			t.Errorf("%s: Components cover %d of %d white pixels", tt.name, area, white)
+		}
+	}
+}
+
+func TestWatershedBoundsMismatch(t *testing.T) {
+	im := diskImage(50, 30, [3]float64{14, 15, 10}, [3]float64{32, 15, 10})
+	dist := DistanceTransform(im)
+	ms, err := Markers(im, dist, 0.7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ls, cs, err := Watershed(im, dist, ms); err != nil || len(cs) != 2 || ls.Bounds() != im.Rect {
+		t.Errorf("got %d Components with bounds %v and %v, want 2 with %v", len(cs), ls.Bounds(), err, im.Rect)
+	}
+
+	other := DistanceTransform(im.SubImage(image.Rect(0, 0, 40, 30)).(*image.Gray))
+	shifted := image.NewGray(im.Rect.Add(image.Pt(3, 2)))
+	copy(shifted.Pix, im.Pix)
+	otherMarkers, err := Markers(shifted, DistanceTransform(shifted), 0.7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"Markers with a smaller Surface", markersErr(Markers(im, other, 0.7))},
+		{"Markers with a zero Surface", markersErr(Markers(im, Surface{}, 0.7))},
+		{"Watershed with a smaller Surface", watershedErr(Watershed(im, other, ms))},
+		{"Watershed with shifted markers", watershedErr(Watershed(im, dist, otherMarkers))},
+		{"Watershed with zero markers", watershedErr(Watershed(im, dist, Labels{}))},
+	}
+	for _, tt := range tests {
+		if tt.err != ErrBoundsMismatch {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.err, ErrBoundsMismatch)
+		}
+	}
+}
+
+// markersErr returns the error of a result of Markers ignoring its Labels
+func markersErr(_ Labels, err error) error {
+	return err
+}
+
+// watershedErr returns the error of a result of Watershed ignoring its Labels and Components
+func watershedErr(_ Labels, _ []Component, err error) error {
+	return err
+}